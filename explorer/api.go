@@ -0,0 +1,157 @@
+package explorer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+var errorInvalidHash = errors.New("error: invalid hash")
+var errorInvalidHeight = errors.New("error: invalid height")
+var errorInvalidPage = errors.New("error: invalid offset or limit")
+
+// API serve a read-only JSON API of the blockchain:
+//
+//	GET /chain/tip
+//	GET /blocks/{hash}
+//	GET /blocks/height/{n}
+//	GET /tx/{id}
+//	GET /address/{addr}?offset=&limit=
+type API struct {
+	bc  *sc.Blockchain
+	mux *http.ServeMux
+}
+
+// NewAPI return the JSON API of the given blockchain
+func NewAPI(bc *sc.Blockchain) *API {
+	api := &API{
+		bc:  bc,
+		mux: http.NewServeMux(),
+	}
+	api.mux.HandleFunc("GET /chain/tip", api.tip)
+	api.mux.HandleFunc("GET /blocks/{hash}", api.blockByHash)
+	api.mux.HandleFunc("GET /blocks/height/{n}", api.blockByHeight)
+	api.mux.HandleFunc("GET /tx/{id}", api.transaction)
+	api.mux.HandleFunc("GET /address/{addr}", api.address)
+	return api
+}
+
+// ServeHTTP route the request to the endpoint
+func (api *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	api.mux.ServeHTTP(w, r)
+}
+
+func (api *API) tip(w http.ResponseWriter, r *http.Request) {
+	tip, err := tipView(api.bc)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, tip)
+}
+
+func (api *API) blockByHash(w http.ResponseWriter, r *http.Request) {
+	hash, err := parseHash(r.PathValue("hash"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	b, err := api.bc.GetBlockByHash(hash)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	api.writeBlock(w, b)
+}
+
+func (api *API) blockByHeight(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidHeight)
+		return
+	}
+	b, err := api.bc.GetBlockByHeight(height)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	api.writeBlock(w, b)
+}
+
+func (api *API) writeBlock(w http.ResponseWriter, b *sc.Block) {
+	view, err := blockView(api.bc, b)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, view)
+}
+
+func (api *API) transaction(w http.ResponseWriter, r *http.Request) {
+	txid, err := parseHash(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	info, err := api.bc.GetTransaction(txid)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, txView(api.bc, info))
+}
+
+func (api *API) address(w http.ResponseWriter, r *http.Request) {
+	q, err := parseHistoryQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	view, err := addressView(api.bc, sc.Address(r.PathValue("addr")), q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, view)
+}
+
+func parseHash(s string) (sc.Hash, error) {
+	h, err := hex.DecodeString(s)
+	if err != nil || len(h) == 0 {
+		return nil, errorInvalidHash
+	}
+	return h, nil
+}
+
+// parseHistoryQuery read the offset and limit of the history page; by default the 50 newest transactions
+func parseHistoryQuery(r *http.Request) (sc.HistoryQuery, error) {
+	q := sc.HistoryQuery{Limit: 50}
+	var err error
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if q.Offset, err = strconv.Atoi(v); err != nil || q.Offset < 0 {
+			return q, errorInvalidPage
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 0 {
+			return q, errorInvalidPage
+		}
+	}
+	return q, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
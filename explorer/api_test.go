@@ -0,0 +1,99 @@
+package explorer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+// testParams the regtest rules without coinbase maturity so the tests spend their mining rewards right away
+var testParams = func() *sc.ChainParams {
+	params := sc.RegTest()
+	params.CoinbaseMaturity = 0
+	return params
+}()
+
+// newTestChain return a chain of 3 blocks where the miner paid alice in the last block
+func newTestChain() (*sc.Blockchain, *sc.Account, *sc.Transaction) {
+	miner := sc.NewAccount()
+	alice := sc.NewAccount()
+	db, _ := sc.NewMemDatabase()
+	bc := sc.NewBlockchain(testParams, miner, db)
+	bc.Mine(1)
+	builder := bc.NewTxBuilder(miner)
+	builder.AddOutput(testParams.Address(alice), 2*sc.Coin)
+	builder.SetFee(sc.Coin)
+	tx, _ := builder.BuildSigned()
+	bc.SendTransaction(tx)
+	return bc, alice, tx
+}
+
+func get(t *testing.T, h http.Handler, path string, v interface{}) int {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if v != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestAPI(t *testing.T) {
+	bc, alice, tx := newTestChain()
+	api := NewAPI(bc)
+
+	var tip Tip
+	get(t, api, "/chain/tip", &tip)
+	if tip.Height != 2 || tip.Hash.String() != bc.BestBlockHash().String() {
+		t.Errorf("unexpected tip %+v", tip)
+	}
+
+	var byHeight, byHash Block
+	get(t, api, "/blocks/height/2", &byHeight)
+	get(t, api, "/blocks/"+tip.Hash.String(), &byHash)
+	if byHeight.Hash.String() != tip.Hash.String() || byHash.Height != 2 || len(byHash.Transactions) != 2 {
+		t.Errorf("unexpected block %+v", byHash)
+	}
+	if fee := byHash.Transactions[1].Fee; fee != sc.Coin {
+		t.Errorf("expected 1 $C fee but got %s", fee)
+	}
+
+	var view Transaction
+	get(t, api, "/tx/"+tx.ID.String(), &view)
+	if view.Height != 2 || view.Confirmations != 1 || view.Inputs[0].Value != testParams.Subsidy(1) || view.Outputs[0].Address.String() != testParams.Address(alice).String() {
+		t.Errorf("unexpected transaction %+v", view)
+	}
+
+	var info AddressInfo
+	get(t, api, "/address/"+testParams.Address(alice).String(), &info)
+	if info.Balance != 2*sc.Coin || len(info.UTXOs) != 1 || info.TxCount != 1 || info.History[0].TxID.String() != tx.ID.String() {
+		t.Errorf("unexpected address info %+v", info)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	bc, _, _ := newTestChain()
+	api := NewAPI(bc)
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/blocks/zz", http.StatusBadRequest},
+		{"/blocks/0102", http.StatusNotFound},
+		{"/blocks/height/3", http.StatusNotFound},
+		{"/blocks/height/x", http.StatusBadRequest},
+		{"/tx/0102", http.StatusNotFound},
+		{"/address/nope", http.StatusBadRequest},
+		{fmt.Sprintf("/address/%s?limit=-1", testParams.Address(sc.NewAccount())), http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		if status := get(t, api, test.path, nil); status != test.status {
+			t.Errorf("%s: expected status %d but got %d", test.path, test.status, status)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package explorer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/golovers/simcoin/sc"
+	"golang.org/x/net/websocket"
+)
+
+// notificationBuffer number of chain events kept for a client which is slow to read
+const notificationBuffer = 64
+
+// the topics a client can subscribe to
+const (
+	topicBlocks  = "blocks"
+	topicTxs     = "txs"
+	topicAddress = "address"
+)
+
+var errorInvalidRequest = errors.New("error: invalid request")
+var errorInvalidTopic = errors.New("error: invalid topic")
+var errorInvalidMethod = errors.New("error: invalid method")
+var errorInvalidAddress = errors.New("error: invalid address")
+
+// SubscribeRequest a message sent by the client to start or stop receiving a topic
+type SubscribeRequest struct {
+	Method  string     `json:"method"`
+	Topic   string     `json:"topic"`
+	Address sc.Address `json:"address,omitempty"`
+}
+
+// Notification a message sent to the client. Type is one of subscribed, unsubscribed, error,
+// block (a block joined the chain), tx (a transaction was confirmed), address (a confirmed
+// transaction touched a subscribed address) or disconnected (a block left the chain; the
+// transactions it confirmed are sent as well on the txs and address topics)
+type Notification struct {
+	Type    string       `json:"type"`
+	Topic   string       `json:"topic,omitempty"`
+	Address sc.Address   `json:"address,omitempty"`
+	Block   *Block       `json:"block,omitempty"`
+	Tx      *Transaction `json:"tx,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// Notifier serve live notifications of the blockchain over WebSocket. Clients send
+//
+//	{"method":"subscribe","topic":"blocks"}
+//	{"method":"subscribe","topic":"txs"}
+//	{"method":"subscribe","topic":"address","address":"..."}
+//
+// and "unsubscribe" the same way, and receive a JSON notification whenever the blockchain
+// accepts a block. Notifications are dropped for clients which don't keep up
+type Notifier struct {
+	bc     *sc.Blockchain
+	server websocket.Server
+}
+
+// NewNotifier return the WebSocket notifications of the given blockchain
+func NewNotifier(bc *sc.Blockchain) *Notifier {
+	n := &Notifier{bc: bc}
+	// any origin is accepted: the notifications are as public as the API
+	n.server = websocket.Server{Handler: n.serve}
+	return n
+}
+
+// ServeHTTP upgrade the request to a WebSocket connection
+func (n *Notifier) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.server.ServeHTTP(w, r)
+}
+
+// topics the subscriptions of a client
+type topics struct {
+	lock      sync.Mutex
+	blocks    bool
+	txs       bool
+	addresses map[string]bool
+}
+
+// update apply the request; subscribed addresses must belong to the network of the given parameters
+func (t *topics) update(req SubscribeRequest, params *sc.ChainParams) error {
+	var on bool
+	switch req.Method {
+	case "subscribe":
+		on = true
+	case "unsubscribe":
+		on = false
+	default:
+		return errorInvalidMethod
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	switch req.Topic {
+	case topicBlocks:
+		t.blocks = on
+	case topicTxs:
+		t.txs = on
+	case topicAddress:
+		if !params.ValidateAddress(req.Address.String()) {
+			return errorInvalidAddress
+		}
+		if on {
+			t.addresses[req.Address.String()] = true
+		} else {
+			delete(t.addresses, req.Address.String())
+		}
+	default:
+		return errorInvalidTopic
+	}
+	return nil
+}
+
+// serve handle one client: its requests are read in the background while the chain events
+// are turned into notifications
+func (n *Notifier) serve(ws *websocket.Conn) {
+	defer ws.Close()
+	sub := n.bc.Subscribe(notificationBuffer, sc.BlockConnected, sc.BlockDisconnected, sc.TransactionAccepted)
+	defer sub.Unsubscribe()
+
+	t := &topics{addresses: make(map[string]bool)}
+	replies := make(chan Notification)
+	done := make(chan struct{})
+	quit := make(chan struct{})
+	defer close(quit)
+	go func() {
+		defer close(done)
+		for {
+			var msg []byte
+			if err := websocket.Message.Receive(ws, &msg); err != nil {
+				return
+			}
+			var req SubscribeRequest
+			reply := Notification{Type: "error", Error: errorInvalidRequest.Error()}
+			if err := json.Unmarshal(msg, &req); err == nil {
+				reply = Notification{Type: req.Method + "d", Topic: req.Topic, Address: req.Address}
+				if err := t.update(req, n.bc.Params()); err != nil {
+					reply = Notification{Type: "error", Topic: req.Topic, Address: req.Address, Error: err.Error()}
+				}
+			}
+			select {
+			case replies <- reply:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		case reply := <-replies:
+			if websocket.JSON.Send(ws, reply) != nil {
+				return
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			for _, notification := range n.notifications(e, t) {
+				if websocket.JSON.Send(ws, notification) != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// notifications return what the client subscribed to about the event
+func (n *Notifier) notifications(e sc.Event, t *topics) []Notification {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	notifications := make([]Notification, 0)
+	switch e.Type {
+	case sc.BlockConnected, sc.BlockDisconnected:
+		typ := "block"
+		if e.Type == sc.BlockDisconnected {
+			typ = "disconnected"
+		}
+		if t.blocks {
+			if view, err := blockView(n.bc, e.Block); err == nil {
+				notifications = append(notifications, Notification{Type: typ, Topic: topicBlocks, Block: view})
+			}
+		}
+		if e.Type == sc.BlockDisconnected {
+			// the transactions of the block are not confirmed anymore
+			for _, tx := range e.Block.Transactions[1:] {
+				notifications = append(notifications, n.txNotifications(t, tx, e, true)...)
+			}
+		}
+	case sc.TransactionAccepted:
+		notifications = append(notifications, n.txNotifications(t, e.Tx, e, false)...)
+	}
+	return notifications
+}
+
+// txNotifications return what the client subscribed to about a transaction of the block of the event,
+// which joined or left the chain
+func (n *Notifier) txNotifications(t *topics, tx *sc.Transaction, e sc.Event, disconnected bool) []Notification {
+	notifications := make([]Notification, 0)
+	if !t.txs && len(t.addresses) == 0 {
+		return notifications
+	}
+	info := &sc.TxInfo{Tx: tx, BlockHash: e.Block.CalHash(), Height: e.Height}
+	txType, addressType := "tx", "address"
+	if disconnected {
+		txType, addressType = "disconnected", "disconnected"
+	} else {
+		info.Confirmations = n.bc.Height() - e.Height + 1
+	}
+	view := txView(n.bc, info)
+	if t.txs {
+		notifications = append(notifications, Notification{Type: txType, Topic: topicTxs, Tx: view})
+	}
+	for _, address := range touchedAddresses(view) {
+		if t.addresses[address.String()] {
+			notifications = append(notifications, Notification{Type: addressType, Topic: topicAddress, Address: address, Tx: view})
+		}
+	}
+	return notifications
+}
+
+// touchedAddresses return the addresses the transaction spends from or pays to, once each
+func touchedAddresses(tx *Transaction) []sc.Address {
+	seen := make(map[string]bool)
+	addresses := make([]sc.Address, 0)
+	add := func(address sc.Address) {
+		if len(address) > 0 && !seen[address.String()] {
+			seen[address.String()] = true
+			addresses = append(addresses, address)
+		}
+	}
+	for _, in := range tx.Inputs {
+		add(in.Address)
+	}
+	for _, out := range tx.Outputs {
+		add(out.Address)
+	}
+	return addresses
+}
@@ -0,0 +1,108 @@
+package explorer
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golovers/simcoin/sc"
+	"golang.org/x/net/websocket"
+)
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(url, "http")+"/ws", "", "http://localhost/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.SetDeadline(time.Now().Add(10 * time.Second))
+	return ws
+}
+
+func request(t *testing.T, ws *websocket.Conn, req SubscribeRequest) Notification {
+	if err := websocket.JSON.Send(ws, req); err != nil {
+		t.Fatal(err)
+	}
+	return receive(t, ws)
+}
+
+func receive(t *testing.T, ws *websocket.Conn) Notification {
+	var n Notification
+	if err := websocket.JSON.Receive(ws, &n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestNotifier(t *testing.T) {
+	bc, alice, _ := newTestChain()
+	server := httptest.NewServer(NewHandler(bc))
+	defer server.Close()
+	ws := dial(t, server.URL)
+	defer ws.Close()
+
+	if n := request(t, ws, SubscribeRequest{Method: "subscribe", Topic: "blocks"}); n.Type != "subscribed" {
+		t.Fatalf("unexpected reply %+v", n)
+	}
+	if n := request(t, ws, SubscribeRequest{Method: "subscribe", Topic: "address", Address: sc.Address("nope")}); n.Type != "error" {
+		t.Errorf("expected an invalid address to be refused but got %+v", n)
+	}
+	if n := request(t, ws, SubscribeRequest{Method: "subscribe", Topic: "address", Address: testParams.Address(alice)}); n.Type != "subscribed" {
+		t.Fatalf("unexpected reply %+v", n)
+	}
+
+	bc.Mine(1)
+	n := receive(t, ws)
+	if n.Type != "block" || n.Block == nil || n.Block.Height != 3 {
+		t.Errorf("expected block 3 but got %+v", n)
+	}
+
+	// alice spends, the notification comes for her address then for the block
+	builder := bc.NewTxBuilder(alice)
+	builder.AddOutput(testParams.Address(sc.NewAccount()), sc.Coin/2)
+	builder.SetFee(sc.Coin / 4)
+	tx, _ := builder.BuildSigned()
+	bc.SendTransaction(tx)
+	n = receive(t, ws)
+	if n.Type != "block" || n.Block.Height != 4 {
+		t.Errorf("expected block 4 but got %+v", n)
+	}
+	n = receive(t, ws)
+	if n.Type != "address" || n.Address.String() != testParams.Address(alice).String() || n.Tx.TxID.String() != tx.ID.String() {
+		t.Errorf("expected the payment of alice but got %+v", n)
+	}
+
+	if n := request(t, ws, SubscribeRequest{Method: "unsubscribe", Topic: "blocks"}); n.Type != "unsubscribed" {
+		t.Fatalf("unexpected reply %+v", n)
+	}
+	if n := request(t, ws, SubscribeRequest{Method: "subscribe", Topic: "txs"}); n.Type != "subscribed" {
+		t.Fatalf("unexpected reply %+v", n)
+	}
+	builder = bc.NewTxBuilder(alice)
+	builder.AddOutput(testParams.Address(sc.NewAccount()), sc.Coin/2)
+	builder.SetFee(sc.Coin / 4)
+	tx, _ = builder.BuildSigned()
+	bc.SendTransaction(tx)
+	if n := receive(t, ws); n.Type != "tx" || n.Tx.Height != 5 {
+		t.Errorf("expected the transaction of block 5 but got %+v", n)
+	}
+	if n := receive(t, ws); n.Type != "address" || n.Tx.Height != 5 {
+		t.Errorf("expected the payment of alice in block 5 but got %+v", n)
+	}
+
+	// the transactions of a block leaving the chain are reported too
+	block, _ := bc.GetBlockByHeight(5)
+	topics := &topics{txs: true, addresses: map[string]bool{testParams.Address(alice).String(): true}}
+	notifications := NewNotifier(bc).notifications(sc.Event{Type: sc.BlockDisconnected, Block: block, Height: 5}, topics)
+	if len(notifications) != 2 {
+		t.Fatalf("expected 2 notifications but got %+v", notifications)
+	}
+	for _, n := range notifications {
+		if n.Type != "disconnected" || n.Tx.TxID.String() != tx.ID.String() || n.Tx.Confirmations != 0 {
+			t.Errorf("expected the transaction of alice to be disconnected but got %+v", n)
+		}
+	}
+	if notifications[0].Topic != "txs" || notifications[1].Topic != "address" {
+		t.Errorf("expected the txs and address topics but got %+v", notifications)
+	}
+}
@@ -0,0 +1,194 @@
+package explorer
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+// recentBlocks number of blocks shown on the home page
+const recentBlocks = 20
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var templateFuncs = template.FuncMap{
+	"short": func(h sc.Hash) string {
+		s := h.String()
+		if len(s) > 16 {
+			return s[:16] + "..."
+		}
+		return s
+	},
+	"date": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+}
+
+// BlockSummary a block as listed on the home page
+type BlockSummary struct {
+	Height    int
+	Hash      sc.Hash
+	Timestamp time.Time
+	TxCount   int
+}
+
+// UI serve a server-rendered HTML explorer of the blockchain. All assets are embedded
+// so it works without internet access
+type UI struct {
+	bc    *sc.Blockchain
+	mux   *http.ServeMux
+	pages map[string]*template.Template
+}
+
+// NewUI return the HTML explorer of the given blockchain
+func NewUI(bc *sc.Blockchain) *UI {
+	ui := &UI{
+		bc:    bc,
+		mux:   http.NewServeMux(),
+		pages: make(map[string]*template.Template),
+	}
+	for _, page := range []string{"index", "block", "tx", "address", "notfound"} {
+		ui.pages[page] = template.Must(template.New(page).Funcs(templateFuncs).
+			ParseFS(templatesFS, "templates/layout.html", "templates/"+page+".html"))
+	}
+	ui.mux.HandleFunc("GET /{$}", ui.index)
+	ui.mux.HandleFunc("GET /block/{hash}", ui.block)
+	ui.mux.HandleFunc("GET /tx/{id}", ui.transaction)
+	ui.mux.HandleFunc("GET /address/{addr}", ui.address)
+	ui.mux.HandleFunc("GET /search", ui.search)
+	return ui
+}
+
+// NewHandler serve the HTML explorer at /, the JSON API under /api/ and the WebSocket
+// notifications at /ws
+func NewHandler(bc *sc.Blockchain) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", NewAPI(bc)))
+	mux.Handle("/ws", NewNotifier(bc))
+	mux.Handle("/", NewUI(bc))
+	return mux
+}
+
+// ServeHTTP route the request to the page
+func (ui *UI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ui.mux.ServeHTTP(w, r)
+}
+
+// index list the most recent blocks, following the previous hashes from the tip
+func (ui *UI) index(w http.ResponseWriter, r *http.Request) {
+	blocks := make([]BlockSummary, 0, recentBlocks)
+	hash := ui.bc.BestBlockHash()
+	for height := ui.bc.Height(); height >= 0 && len(blocks) < recentBlocks; height-- {
+		b, err := ui.bc.GetBlockByHash(hash)
+		if err != nil {
+			break
+		}
+		blocks = append(blocks, BlockSummary{
+			Height:    height,
+			Hash:      hash,
+			Timestamp: b.Timestamp,
+			TxCount:   len(b.Transactions),
+		})
+		hash = b.PrevHash
+	}
+	ui.render(w, http.StatusOK, "index", blocks)
+}
+
+func (ui *UI) block(w http.ResponseWriter, r *http.Request) {
+	hash, err := parseHash(r.PathValue("hash"))
+	if err != nil {
+		ui.notFound(w, r.PathValue("hash"))
+		return
+	}
+	b, err := ui.bc.GetBlockByHash(hash)
+	if err != nil {
+		ui.notFound(w, r.PathValue("hash"))
+		return
+	}
+	ui.renderBlock(w, b)
+}
+
+func (ui *UI) renderBlock(w http.ResponseWriter, b *sc.Block) {
+	view, err := blockView(ui.bc, b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ui.render(w, http.StatusOK, "block", view)
+}
+
+func (ui *UI) transaction(w http.ResponseWriter, r *http.Request) {
+	txid, err := parseHash(r.PathValue("id"))
+	if err != nil {
+		ui.notFound(w, r.PathValue("id"))
+		return
+	}
+	info, err := ui.bc.GetTransaction(txid)
+	if err != nil {
+		ui.notFound(w, r.PathValue("id"))
+		return
+	}
+	ui.render(w, http.StatusOK, "tx", txView(ui.bc, info))
+}
+
+func (ui *UI) address(w http.ResponseWriter, r *http.Request) {
+	q, err := parseHistoryQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	view, err := addressView(ui.bc, sc.Address(r.PathValue("addr")), q)
+	if err != nil {
+		ui.notFound(w, r.PathValue("addr"))
+		return
+	}
+	ui.render(w, http.StatusOK, "address", view)
+}
+
+// search redirect to the page of the block height, block hash, txid or address
+func (ui *UI) search(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if height, err := strconv.Atoi(q); err == nil {
+		if b, err := ui.bc.GetBlockByHeight(height); err == nil {
+			http.Redirect(w, r, "/block/"+b.CalHash().String(), http.StatusSeeOther)
+			return
+		}
+	}
+	if hash, err := parseHash(q); err == nil {
+		if _, err := ui.bc.GetBlockByHash(hash); err == nil {
+			http.Redirect(w, r, "/block/"+hash.String(), http.StatusSeeOther)
+			return
+		}
+		if _, err := ui.bc.GetTransaction(hash); err == nil {
+			http.Redirect(w, r, "/tx/"+hash.String(), http.StatusSeeOther)
+			return
+		}
+	}
+	if ui.bc.Params().ValidateAddress(q) {
+		http.Redirect(w, r, "/address/"+q, http.StatusSeeOther)
+		return
+	}
+	ui.notFound(w, q)
+}
+
+func (ui *UI) notFound(w http.ResponseWriter, q string) {
+	ui.render(w, http.StatusNotFound, "notfound", q)
+}
+
+func (ui *UI) render(w http.ResponseWriter, status int, page string, data interface{}) {
+	var buf bytes.Buffer
+	if err := ui.pages[page].ExecuteTemplate(&buf, "layout", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
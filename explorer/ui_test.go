@@ -0,0 +1,65 @@
+package explorer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+func TestUIPages(t *testing.T) {
+	bc, alice, tx := newTestChain()
+	h := NewHandler(bc)
+	tip := bc.BestBlockHash().String()
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/", http.StatusOK, tip},
+		{"/block/" + tip, http.StatusOK, tx.ID.String()},
+		{"/tx/" + tx.ID.String(), http.StatusOK, testParams.Address(alice).String()},
+		{"/address/" + testParams.Address(alice).String(), http.StatusOK, "2 $C"},
+		{"/block/0102", http.StatusNotFound, "Not found"},
+		{"/api/chain/tip", http.StatusOK, tip},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rec.Code != test.status || !strings.Contains(rec.Body.String(), test.contains) {
+			t.Errorf("%s: expected status %d containing %q but got %d", test.path, test.status, test.contains, rec.Code)
+		}
+	}
+}
+
+func TestUISearch(t *testing.T) {
+	bc, alice, tx := newTestChain()
+	ui := NewUI(bc)
+	tip := bc.BestBlockHash().String()
+	tests := []struct {
+		q        string
+		location string
+	}{
+		{"2", "/block/" + tip},
+		{tip, "/block/" + tip},
+		{tx.ID.String(), "/tx/" + tx.ID.String()},
+		{testParams.Address(alice).String(), "/address/" + testParams.Address(alice).String()},
+		{"42", ""},
+		{sc.Hash{1, 2}.String(), ""},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		ui.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q="+test.q, nil))
+		if test.location == "" {
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected not found but got %d", test.q, rec.Code)
+			}
+			continue
+		}
+		if location := rec.Header().Get("Location"); location != test.location {
+			t.Errorf("%s: expected redirect to %s but got %q", test.q, test.location, location)
+		}
+	}
+}
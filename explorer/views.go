@@ -0,0 +1,221 @@
+package explorer
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+// Tip the last block of the chain
+type Tip struct {
+	Height    int       `json:"height"`
+	Hash      sc.Hash   `json:"hash"`
+	Timestamp time.Time `json:"time"`
+}
+
+// Block a block with its position in the chain and its transactions
+type Block struct {
+	Hash          sc.Hash        `json:"hash"`
+	PrevHash      sc.Hash        `json:"previousblockhash"`
+	Height        int            `json:"height"`
+	Confirmations int            `json:"confirmations"`
+	Timestamp     time.Time      `json:"time"`
+	Difficulty    int            `json:"difficulty"`
+	Nonce         int            `json:"nonce"`
+	MerkleRoot    sc.Hash        `json:"merkleroot"`
+	Transactions  []*Transaction `json:"tx"`
+}
+
+// Input a transaction input with the value and the address of the output it spends
+type Input struct {
+	TxID     sc.Hash    `json:"txid,omitempty"`
+	Vout     int        `json:"vout"`
+	Coinbase bool       `json:"coinbase,omitempty"`
+	Value    sc.Amount  `json:"value"`
+	Address  sc.Address `json:"address,omitempty"`
+}
+
+// Output a transaction output; Data is set for OP_RETURN outputs
+type Output struct {
+	N            int        `json:"n"`
+	Value        sc.Amount  `json:"value"`
+	Address      sc.Address `json:"address,omitempty"`
+	ScriptPubKey string     `json:"scriptPubKey"`
+	Data         string     `json:"data,omitempty"`
+}
+
+// Transaction a confirmed transaction with its inputs resolved
+type Transaction struct {
+	TxID          sc.Hash   `json:"txid"`
+	BlockHash     sc.Hash   `json:"blockhash"`
+	Height        int       `json:"height"`
+	Confirmations int       `json:"confirmations"`
+	Coinbase      bool      `json:"coinbase"`
+	Size          int       `json:"size"`
+	Fee           sc.Amount `json:"fee"`
+	Inputs        []Input   `json:"vin"`
+	Outputs       []Output  `json:"vout"`
+}
+
+// UTXO an output the address can spend
+type UTXO struct {
+	TxID  sc.Hash   `json:"txid"`
+	Vout  int       `json:"vout"`
+	Value sc.Amount `json:"value"`
+}
+
+// AddressInfo the balance, the unspent outputs and a page of the history of an address.
+// The history goes from the newest to the oldest transaction
+type AddressInfo struct {
+	Address  sc.Address     `json:"address"`
+	Balance  sc.Amount      `json:"balance"`
+	Immature sc.Amount      `json:"immature"`
+	UTXOs    []UTXO         `json:"utxos"`
+	TxCount  int            `json:"txcount"`
+	History  []sc.AddressTx `json:"history"`
+}
+
+func tipView(bc *sc.Blockchain) (*Tip, error) {
+	height, hash := bc.Tip()
+	b, err := bc.GetBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &Tip{
+		Height:    height,
+		Hash:      hash,
+		Timestamp: b.Timestamp,
+	}, nil
+}
+
+func blockView(bc *sc.Blockchain, b *sc.Block) (*Block, error) {
+	hash := b.CalHash()
+	height, err := bc.BlockHeight(hash)
+	if err != nil {
+		return nil, err
+	}
+	confirmations, err := bc.Confirmations(hash)
+	if err != nil {
+		return nil, err
+	}
+	view := &Block{
+		Hash:          hash,
+		PrevHash:      b.PrevHash,
+		Height:        height,
+		Confirmations: confirmations,
+		Timestamp:     b.Timestamp,
+		Difficulty:    b.Difficulty,
+		Nonce:         b.Nonce,
+		MerkleRoot:    b.MerkleRoot(),
+		Transactions:  make([]*Transaction, 0, len(b.Transactions)),
+	}
+	// the transactions of a block off the active chain are not confirmed
+	if confirmations < 0 {
+		confirmations = 0
+	}
+	prevs := prevTransactions(bc, b.Transactions...)
+	for _, tx := range b.Transactions {
+		view.Transactions = append(view.Transactions, resolvedTxView(&sc.TxInfo{
+			Tx:            tx,
+			BlockHash:     hash,
+			Height:        height,
+			Confirmations: confirmations,
+		}, prevs))
+	}
+	return view, nil
+}
+
+// txView resolve the outputs spent by the inputs of the transaction
+func txView(bc *sc.Blockchain, info *sc.TxInfo) *Transaction {
+	return resolvedTxView(info, prevTransactions(bc, info.Tx))
+}
+
+// prevTransactions look up the transactions whose outputs are spent by the given transactions at once
+func prevTransactions(bc *sc.Blockchain, txs ...*sc.Transaction) map[string]*sc.TxInfo {
+	txids := make([]sc.Hash, 0)
+	for _, tx := range txs {
+		for _, vin := range tx.Vin {
+			if !vin.IsCoinBase() {
+				txids = append(txids, vin.Txid)
+			}
+		}
+	}
+	return bc.GetTransactions(txids)
+}
+
+// resolvedTxView build the view of the transaction taking the spent outputs from the given transactions
+func resolvedTxView(info *sc.TxInfo, prevs map[string]*sc.TxInfo) *Transaction {
+	tx := info.Tx
+	view := &Transaction{
+		TxID:          tx.ID,
+		BlockHash:     info.BlockHash,
+		Height:        info.Height,
+		Confirmations: info.Confirmations,
+		Coinbase:      tx.IsCoinBase(),
+		Size:          tx.Size(),
+		Inputs:        make([]Input, 0, len(tx.Vin)),
+		Outputs:       make([]Output, 0, len(tx.Vout)),
+	}
+	in := sc.Amount(0)
+	for _, vin := range tx.Vin {
+		if vin.IsCoinBase() {
+			view.Inputs = append(view.Inputs, Input{Vout: vin.Vout, Coinbase: true})
+			continue
+		}
+		input := Input{TxID: vin.Txid, Vout: vin.Vout}
+		if prev, ok := prevs[vin.Txid.String()]; ok && vin.Vout < len(prev.Tx.Vout) {
+			out := prev.Tx.Vout[vin.Vout]
+			input.Value = out.Value
+			input.Address = out.Address()
+		}
+		in += input.Value
+		view.Inputs = append(view.Inputs, input)
+	}
+	for n, vout := range tx.Vout {
+		output := Output{
+			N:            n,
+			Value:        vout.Value,
+			Address:      vout.Address(),
+			ScriptPubKey: vout.ScriptPubKey,
+		}
+		if vout.IsDataCarrier() {
+			output.Data = hex.EncodeToString(vout.Data())
+		}
+		view.Outputs = append(view.Outputs, output)
+	}
+	if !view.Coinbase {
+		view.Fee = in - tx.OutputValue()
+	}
+	return view
+}
+
+func addressView(bc *sc.Blockchain, address sc.Address, q sc.HistoryQuery) (*AddressInfo, error) {
+	balance, immature, err := bc.BalanceOf(address)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := bc.UTXOs(address)
+	if err != nil {
+		return nil, err
+	}
+	history, err := bc.AddressHistory(address)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	view := &AddressInfo{
+		Address:  address,
+		Balance:  balance,
+		Immature: immature,
+		UTXOs:    make([]UTXO, 0, len(utxos)),
+		TxCount:  len(history),
+		History:  q.Apply(history),
+	}
+	for _, u := range utxos {
+		view.UTXOs = append(view.UTXOs, UTXO{TxID: u.TxIn.Txid, Vout: u.TxIn.Vout, Value: u.Value})
+	}
+	return view, nil
+}
@@ -0,0 +1,158 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+// Client call the JSON-RPC API of a simcoin node
+type Client struct {
+	url      string
+	user     string
+	password string
+	http     *http.Client
+	id       int64
+}
+
+// NewClient return a client for the server at the given URL. The user and password are sent
+// using basic auth when user is not empty
+func NewClient(url, user, password string) *Client {
+	return &Client{
+		url:      url,
+		user:     user,
+		password: password,
+		http:     http.DefaultClient,
+	}
+}
+
+// Call run the method with the given positional params and decode its result into result
+func (c *Client) Call(method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	id, _ := json.Marshal(atomic.AddInt64(&c.id, 1))
+	body, err := json.Marshal(Request{
+		JSONRPC: version,
+		Method:  method,
+		Params:  rawParams,
+		ID:      id,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error: rpc server returned %s", httpResp.Status)
+	}
+	var resp Response
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// GetBlockCount return the height of the last block
+func (c *Client) GetBlockCount() (int, error) {
+	var height int
+	err := c.Call("getblockcount", &height)
+	return height, err
+}
+
+// GetBestBlockHash return the hash of the last block
+func (c *Client) GetBestBlockHash() (sc.Hash, error) {
+	var hash sc.Hash
+	err := c.Call("getbestblockhash", &hash)
+	return hash, err
+}
+
+// GetBlock return the block with the given hash
+func (c *Client) GetBlock(hash sc.Hash) (*Block, error) {
+	var b Block
+	if err := c.Call("getblock", &b, hash); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetTransaction return the confirmed transaction with the given id
+func (c *Client) GetTransaction(txid sc.Hash) (*Transaction, error) {
+	var tx Transaction
+	if err := c.Call("gettransaction", &tx, txid); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetBalance return the balance of the wallet account or the address
+func (c *Client) GetBalance(accountOrAddress string) (*Balance, error) {
+	var balance Balance
+	if err := c.Call("getbalance", &balance, accountOrAddress); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// SendToAddress pay the address from the wallet account and return the txid
+func (c *Client) SendToAddress(from string, to sc.Address, amount, fee sc.Amount) (sc.Hash, error) {
+	var txid sc.Hash
+	err := c.Call("sendtoaddress", &txid, from, to, amount, fee)
+	return txid, err
+}
+
+// GetNewAddress create a wallet account and return its address
+func (c *Client) GetNewAddress(account string) (sc.Address, error) {
+	var address sc.Address
+	err := c.Call("getnewaddress", &address, account)
+	return address, err
+}
+
+// Generate mine n blocks and return their hashes
+func (c *Client) Generate(n int) ([]sc.Hash, error) {
+	var hashes []sc.Hash
+	err := c.Call("generate", &hashes, n)
+	return hashes, err
+}
+
+// ValidateAddress check the address and its checksum
+func (c *Client) ValidateAddress(address string) (*AddressValidation, error) {
+	var v AddressValidation
+	if err := c.Call("validateaddress", &v, address); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// SendRawTransaction send the signed transaction to the node and return its txid
+func (c *Client) SendRawTransaction(tx *sc.Transaction) (sc.Hash, error) {
+	var txid sc.Hash
+	err := c.Call("sendrawtransaction", &txid, hex.EncodeToString(tx.Serialize()))
+	return txid, err
+}
@@ -0,0 +1,352 @@
+package rpc
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+// maxRequestSize the largest request body the server reads
+const maxRequestSize = 1 << 20
+
+// maxGenerate the most blocks a single generate call mines
+const maxGenerate = 1000
+
+var errorInvalidParams = errors.New("error: invalid params")
+
+// handler run a method with the raw params of the request
+type handler func(params json.RawMessage) (interface{}, error)
+
+// Server serve the JSON-RPC 2.0 API of a node and its wallet over HTTP.
+// Calls are run one at a time since the wallet is not safe for concurrent use
+type Server struct {
+	bc       *sc.Blockchain
+	wallet   *sc.MemWallet
+	user     string
+	password string
+	lock     sync.Mutex
+	methods  map[string]handler
+}
+
+// NewServer return a server for the given blockchain and wallet. When user is not empty
+// the clients must authenticate with the given user and password using basic auth
+func NewServer(bc *sc.Blockchain, wallet *sc.MemWallet, user, password string) *Server {
+	s := &Server{
+		bc:       bc,
+		wallet:   wallet,
+		user:     user,
+		password: password,
+	}
+	s.methods = map[string]handler{
+		"getblockcount":      s.getBlockCount,
+		"getbestblockhash":   s.getBestBlockHash,
+		"getblock":           s.getBlock,
+		"gettransaction":     s.getTransaction,
+		"getbalance":         s.getBalance,
+		"sendtoaddress":      s.sendToAddress,
+		"getnewaddress":      s.getNewAddress,
+		"generate":           s.generate,
+		"validateaddress":    s.validateAddress,
+		"sendrawtransaction": s.sendRawTransaction,
+	}
+	return s
+}
+
+// ListenAndServe listen on the given TCP address and serve the API
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s)
+}
+
+// ServeHTTP handle a single request or a batch of requests posted as JSON
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="simcoin"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var resp interface{}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		resp = s.handleBatch(trimmed)
+	} else if r := s.handleRaw(trimmed); r != nil {
+		resp = r
+	}
+	if resp == nil {
+		// only notifications, nothing to answer
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// authorized check the basic auth credentials of the request
+func (s *Server) authorized(r *http.Request) bool {
+	if s.user == "" {
+		return true
+	}
+	user, password, ok := r.BasicAuth()
+	return ok && subtle.ConstantTimeCompare([]byte(user), []byte(s.user)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(s.password)) == 1
+}
+
+// handleBatch handle every request of the batch; notifications get no response
+func (s *Server) handleBatch(body []byte) interface{} {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(body, &raws); err != nil {
+		return errorResponse(nil, CodeParseError, err.Error())
+	}
+	if len(raws) == 0 {
+		return errorResponse(nil, CodeInvalidRequest, "empty batch")
+	}
+	responses := make([]*Response, 0, len(raws))
+	for _, raw := range raws {
+		if resp := s.handleRaw(raw); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// handleRaw decode and run a single request; it return nil for notifications
+func (s *Server) handleRaw(raw []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, CodeParseError, err.Error())
+	}
+	if req.JSONRPC != version || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid request")
+	}
+	resp := s.handle(&req)
+	if req.ID == nil {
+		return nil
+	}
+	return resp
+}
+
+// handle run the method of the request
+func (s *Server) handle(req *Request) *Response {
+	method, ok := s.methods[req.Method]
+	if !ok {
+		return errorResponse(req.ID, CodeMethodNotFound, "method not found: "+req.Method)
+	}
+	result, err := s.call(method, req.Params)
+	if err == errorInvalidParams {
+		return errorResponse(req.ID, CodeInvalidParams, err.Error())
+	}
+	if err != nil {
+		return errorResponse(req.ID, CodeServerError, err.Error())
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, CodeInternalError, err.Error())
+	}
+	return &Response{JSONRPC: version, Result: data, ID: req.ID}
+}
+
+// call run the method one at a time; the blockchain panics on invalid payments so the panic
+// is reported as an error
+func (s *Server) call(method handler, params json.RawMessage) (result interface{}, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = errors.New("error: internal error")
+			}
+		}
+	}()
+	return method(params)
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	return &Response{
+		JSONRPC: version,
+		Error:   &Error{Code: code, Message: message},
+		ID:      id,
+	}
+}
+
+// parseParams decode the positional params into args; the params after the required ones are optional
+func parseParams(params json.RawMessage, required int, args ...interface{}) error {
+	var values []json.RawMessage
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &values); err != nil {
+			return errorInvalidParams
+		}
+	}
+	if len(values) < required || len(values) > len(args) {
+		return errorInvalidParams
+	}
+	for i, v := range values {
+		if err := json.Unmarshal(v, args[i]); err != nil {
+			return errorInvalidParams
+		}
+	}
+	return nil
+}
+
+// getblockcount return the height of the last block
+func (s *Server) getBlockCount(params json.RawMessage) (interface{}, error) {
+	if err := parseParams(params, 0); err != nil {
+		return nil, err
+	}
+	return s.bc.Height(), nil
+}
+
+// getbestblockhash return the hash of the last block
+func (s *Server) getBestBlockHash(params json.RawMessage) (interface{}, error) {
+	if err := parseParams(params, 0); err != nil {
+		return nil, err
+	}
+	return s.bc.BestBlockHash(), nil
+}
+
+// getblock [hash] return the block with the given hash
+func (s *Server) getBlock(params json.RawMessage) (interface{}, error) {
+	var hash sc.Hash
+	if err := parseParams(params, 1, &hash); err != nil {
+		return nil, err
+	}
+	b, err := s.bc.GetBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	height, err := s.bc.BlockHeight(hash)
+	if err != nil {
+		return nil, err
+	}
+	confirmations, err := s.bc.Confirmations(hash)
+	if err != nil {
+		return nil, err
+	}
+	return newBlock(b, height, confirmations), nil
+}
+
+// gettransaction [txid] return the confirmed transaction with the given id
+func (s *Server) getTransaction(params json.RawMessage) (interface{}, error) {
+	var txid sc.Hash
+	if err := parseParams(params, 1, &txid); err != nil {
+		return nil, err
+	}
+	info, err := s.bc.GetTransaction(txid)
+	if err != nil {
+		return nil, err
+	}
+	return newTransaction(info), nil
+}
+
+// getbalance [account or address] return the balance of the wallet account or of any address
+func (s *Server) getBalance(params json.RawMessage) (interface{}, error) {
+	var name string
+	if err := parseParams(params, 1, &name); err != nil {
+		return nil, err
+	}
+	address := sc.Address(name)
+	if !s.bc.Params().ValidateAddress(name) {
+		var err error
+		if address, err = s.wallet.AddressOf(name); err != nil {
+			return nil, err
+		}
+	}
+	available, immature, err := s.bc.BalanceOf(address)
+	if err != nil {
+		return nil, err
+	}
+	return Balance{Available: available, Immature: immature}, nil
+}
+
+// sendtoaddress [from, address, amount, fee] pay the address from the wallet account and return the txid
+func (s *Server) sendToAddress(params json.RawMessage) (interface{}, error) {
+	var from string
+	var to sc.Address
+	var amount, fee sc.Amount
+	if err := parseParams(params, 3, &from, &to, &amount, &fee); err != nil {
+		return nil, err
+	}
+	tx, err := s.wallet.SendTo(from, to, amount, fee)
+	if err != nil {
+		return nil, err
+	}
+	return tx.ID, nil
+}
+
+// getnewaddress [account] create a wallet account and return its address
+func (s *Server) getNewAddress(params json.RawMessage) (interface{}, error) {
+	var name string
+	if err := parseParams(params, 1, &name); err != nil {
+		return nil, err
+	}
+	return s.wallet.NewAddress(name)
+}
+
+// generate [n] mine n blocks, at most maxGenerate, and return their hashes
+func (s *Server) generate(params json.RawMessage) (interface{}, error) {
+	var n int
+	if err := parseParams(params, 1, &n); err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxGenerate {
+		return nil, errorInvalidParams
+	}
+	hashes := make([]sc.Hash, 0, n)
+	for i := 0; i < n; i++ {
+		if err := s.bc.Mine(1); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, s.bc.BestBlockHash())
+	}
+	return hashes, nil
+}
+
+// validateaddress [address] check the address, its checksum and that it belongs to the network
+func (s *Server) validateAddress(params json.RawMessage) (interface{}, error) {
+	var address string
+	if err := parseParams(params, 1, &address); err != nil {
+		return nil, err
+	}
+	if !s.bc.Params().ValidateAddress(address) {
+		return AddressValidation{IsValid: false}, nil
+	}
+	return AddressValidation{IsValid: true, Address: address}, nil
+}
+
+// sendrawtransaction [hex] validate the signed serialized transaction, mine it and return its txid
+func (s *Server) sendRawTransaction(params json.RawMessage) (interface{}, error) {
+	var raw string
+	if err := parseParams(params, 1, &raw); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, errorInvalidParams
+	}
+	tx, err := sc.DeserializeTransaction(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.bc.SendTransaction(tx); err != nil {
+		return nil, err
+	}
+	return tx.ID, nil
+}
@@ -0,0 +1,158 @@
+package rpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+// testParams the regtest rules without coinbase maturity so the tests spend their mining rewards right away
+var testParams = func() *sc.ChainParams {
+	params := sc.RegTest()
+	params.CoinbaseMaturity = 0
+	return params
+}()
+
+func newTestServer(t *testing.T) (*httptest.Server, *sc.Blockchain, *sc.Account) {
+	miner := sc.NewAccount()
+	db, _ := sc.NewMemDatabase()
+	bc := sc.NewBlockchain(testParams, miner, db)
+	wallet := sc.NewMemWallet(bc)
+	wallet.Add("miner", miner)
+	ts := httptest.NewServer(NewServer(bc, wallet, "user", "secret"))
+	t.Cleanup(ts.Close)
+	return ts, bc, miner
+}
+
+func TestNodeMethods(t *testing.T) {
+	ts, bc, _ := newTestServer(t)
+	client := NewClient(ts.URL, "user", "secret")
+
+	hashes, err := client.Generate(2)
+	if err != nil || len(hashes) != 2 {
+		t.Fatalf("expected 2 blocks but got %v, %v", hashes, err)
+	}
+	height, err := client.GetBlockCount()
+	if err != nil || height != 2 {
+		t.Errorf("expected height 2 but got %d, %v", height, err)
+	}
+	best, err := client.GetBestBlockHash()
+	if err != nil || best.String() != hashes[1].String() {
+		t.Errorf("expected best block %s but got %s, %v", hashes[1], best, err)
+	}
+	b, err := client.GetBlock(hashes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Height != 1 || b.Confirmations != 2 || len(b.Transactions) != 1 {
+		t.Errorf("unexpected block %+v", b)
+	}
+	tx, err := client.GetTransaction(b.Transactions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tx.Vin[0].Coinbase || tx.Vout[0].Value != testParams.Subsidy(1) || tx.BlockHash.String() != hashes[0].String() {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	if _, err := client.GetBlock(sc.Hash{1, 2, 3}); err == nil {
+		t.Errorf("unknown block should fail")
+	}
+	if bc.Height() != 2 {
+		t.Errorf("expected the node to have 2 blocks")
+	}
+}
+
+func TestWalletMethods(t *testing.T) {
+	ts, bc, miner := newTestServer(t)
+	client := NewClient(ts.URL, "user", "secret")
+	client.Generate(1)
+
+	alice, err := client.GetNewAddress("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := client.ValidateAddress(string(alice))
+	if err != nil || !v.IsValid {
+		t.Errorf("expected a valid address but got %v, %v", v, err)
+	}
+	if v, _ := client.ValidateAddress("nope"); v.IsValid {
+		t.Errorf("expected an invalid address")
+	}
+	if v, _ := client.ValidateAddress(sc.MainNet().Address(sc.NewAccount()).String()); v.IsValid {
+		t.Errorf("expected an address of another network to be invalid")
+	}
+	txid, err := client.SendToAddress("miner", alice, 2*sc.Coin, sc.Coin/2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTransaction(txid); err != nil {
+		t.Errorf("sent transaction should be confirmed: %v", err)
+	}
+	balance, err := client.GetBalance("alice")
+	if err != nil || balance.Available != 2*sc.Coin {
+		t.Errorf("expected 2 $C but got %v, %v", balance, err)
+	}
+	balance, err = client.GetBalance(string(alice))
+	if err != nil || balance.Available != 2*sc.Coin {
+		t.Errorf("expected 2 $C but got %v, %v", balance, err)
+	}
+	if _, err := client.SendToAddress("miner", alice, sc.MaxAmount, 0); err == nil {
+		t.Errorf("spending more than the balance should fail")
+	}
+
+	builder := bc.NewTxBuilder(miner)
+	builder.AddOutput(alice, sc.Coin)
+	tx, err := builder.BuildSigned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid, err = client.SendRawTransaction(tx)
+	if err != nil || txid.String() != tx.ID.String() {
+		t.Fatalf("expected txid %s but got %s, %v", tx.ID, txid, err)
+	}
+	if _, err := client.SendRawTransaction(tx); err == nil {
+		t.Errorf("double spend should be rejected")
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	ts, _, _ := newTestServer(t)
+
+	if _, err := NewClient(ts.URL, "user", "wrong").GetBlockCount(); err == nil {
+		t.Errorf("wrong password should be rejected")
+	}
+	client := NewClient(ts.URL, "user", "secret")
+	err := client.Call("nosuchmethod", nil)
+	if e, ok := err.(*Error); !ok || e.Code != CodeMethodNotFound {
+		t.Errorf("expected method not found but got %v", err)
+	}
+	err = client.Call("getblock", nil)
+	if e, ok := err.(*Error); !ok || e.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params but got %v", err)
+	}
+	err = client.Call("generate", nil, maxGenerate+1)
+	if e, ok := err.(*Error); !ok || e.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params for too many blocks but got %v", err)
+	}
+
+	batch := `[{"jsonrpc":"2.0","method":"getblockcount","id":1},{"jsonrpc":"2.0","method":"generate","params":[1]}]`
+	req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(batch))
+	req.SetBasicAuth("user", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	// the notification is run but gets no response
+	if got := strings.TrimSpace(string(body)); got != `[{"jsonrpc":"2.0","result":0,"id":1}]` {
+		t.Errorf("unexpected batch response %s", got)
+	}
+	if height, _ := client.GetBlockCount(); height != 1 {
+		t.Errorf("expected the notification to mine a block but got height %d", height)
+	}
+}
@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golovers/simcoin/sc"
+)
+
+const version = "2.0"
+
+// JSON-RPC 2.0 error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is returned when the node or the wallet refuses the call
+	CodeServerError = -32000
+)
+
+// Request a JSON-RPC 2.0 request; a request without id is a notification and gets no response
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// Response a JSON-RPC 2.0 response carrying either the result or the error
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Error a JSON-RPC 2.0 error
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Block a block as returned by getblock
+type Block struct {
+	Hash          sc.Hash   `json:"hash"`
+	PrevHash      sc.Hash   `json:"previousblockhash"`
+	Height        int       `json:"height"`
+	Confirmations int       `json:"confirmations"`
+	Timestamp     time.Time `json:"time"`
+	Difficulty    int       `json:"difficulty"`
+	Nonce         int       `json:"nonce"`
+	MerkleRoot    sc.Hash   `json:"merkleroot"`
+	Transactions  []sc.Hash `json:"tx"`
+}
+
+// TxIn a transaction input as returned by gettransaction
+type TxIn struct {
+	TxID      sc.Hash `json:"txid,omitempty"`
+	Vout      int     `json:"vout"`
+	ScriptSig string  `json:"scriptSig,omitempty"`
+	Coinbase  bool    `json:"coinbase,omitempty"`
+}
+
+// TxOut a transaction output as returned by gettransaction
+type TxOut struct {
+	Value        sc.Amount `json:"value"`
+	N            int       `json:"n"`
+	ScriptPubKey string    `json:"scriptPubKey"`
+}
+
+// Transaction a confirmed transaction as returned by gettransaction
+type Transaction struct {
+	TxID          sc.Hash `json:"txid"`
+	BlockHash     sc.Hash `json:"blockhash"`
+	Height        int     `json:"height"`
+	Confirmations int     `json:"confirmations"`
+	Vin           []TxIn  `json:"vin"`
+	Vout          []TxOut `json:"vout"`
+	Hex           string  `json:"hex"`
+}
+
+// Balance the balance of an account or an address as returned by getbalance
+type Balance struct {
+	Available sc.Amount `json:"available"`
+	Immature  sc.Amount `json:"immature"`
+}
+
+// AddressValidation the result of validateaddress
+type AddressValidation struct {
+	IsValid bool   `json:"isvalid"`
+	Address string `json:"address,omitempty"`
+}
+
+func newBlock(b *sc.Block, height int, confirmations int) *Block {
+	txs := make([]sc.Hash, 0, len(b.Transactions))
+	for _, tx := range b.Transactions {
+		txs = append(txs, tx.ID)
+	}
+	return &Block{
+		Hash:          b.CalHash(),
+		PrevHash:      b.PrevHash,
+		Height:        height,
+		Confirmations: confirmations,
+		Timestamp:     b.Timestamp,
+		Difficulty:    b.Difficulty,
+		Nonce:         b.Nonce,
+		MerkleRoot:    b.MerkleRoot(),
+		Transactions:  txs,
+	}
+}
+
+func newTransaction(info *sc.TxInfo) *Transaction {
+	tx := &Transaction{
+		TxID:          info.Tx.ID,
+		BlockHash:     info.BlockHash,
+		Height:        info.Height,
+		Confirmations: info.Confirmations,
+		Vin:           make([]TxIn, 0, len(info.Tx.Vin)),
+		Vout:          make([]TxOut, 0, len(info.Tx.Vout)),
+		Hex:           hex.EncodeToString(info.Tx.Serialize()),
+	}
+	for _, vin := range info.Tx.Vin {
+		if vin.IsCoinBase() {
+			tx.Vin = append(tx.Vin, TxIn{Vout: vin.Vout, Coinbase: true})
+			continue
+		}
+		tx.Vin = append(tx.Vin, TxIn{
+			TxID:      vin.Txid,
+			Vout:      vin.Vout,
+			ScriptSig: hex.EncodeToString(vin.ScriptSig),
+		})
+	}
+	for n, vout := range info.Tx.Vout {
+		tx.Vout = append(tx.Vout, TxOut{
+			Value:        vout.Value,
+			N:            n,
+			ScriptPubKey: vout.ScriptPubKey,
+		})
+	}
+	return tx
+}
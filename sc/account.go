@@ -5,11 +5,15 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
 )
 
-const version = byte(0x00)
 const addressChecksumLen = 4
 
+var errorInvalidKey = errors.New("error: invalid private key")
+
 // Account account
 type Account struct {
 	PubKey PubKey
@@ -31,14 +35,44 @@ func newKeyPair() (ecdsa.PrivateKey, PubKey) {
 	if err != nil {
 		panic(err)
 	}
-	pub := append(priv.PublicKey.X.Bytes(), priv.PublicKey.Y.Bytes()...)
-	return *priv, pub[:]
+	return *priv, toPubKey(priv.PublicKey)
+
+}
 
+// toPubKey serialize the public key; the coordinates are padded so the public key always has the same length
+func toPubKey(pub ecdsa.PublicKey) PubKey {
+	return append(pub.X.FillBytes(make([]byte, 32)), pub.Y.FillBytes(make([]byte, 32))...)
 }
 
-// GetAddress get address
+// Export return the private key of the account as a hex string
+func (acc *Account) Export() string {
+	return hex.EncodeToString(acc.PriKey.D.FillBytes(make([]byte, 32)))
+}
+
+// ImportAccount return the account of the given hex encoded private key
+func ImportAccount(key string) (*Account, error) {
+	d, err := hex.DecodeString(key)
+	if err != nil || len(d) != 32 {
+		return nil, errorInvalidKey
+	}
+	curve := elliptic.P256()
+	priv := ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	if priv.D.Sign() == 0 || priv.D.Cmp(curve.Params().N) >= 0 {
+		return nil, errorInvalidKey
+	}
+	priv.PublicKey.Curve = curve
+	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(d)
+	return &Account{PubKey: toPubKey(priv.PublicKey), PriKey: priv}, nil
+}
+
+// GetAddress get address on the main network; ChainParams.Address return the address on another network
 func (acc *Account) GetAddress() Address {
-	payload := hash160(acc.PubKey)
+	return pubKeyToAddress(acc.PubKey, mainNetAddressVersion)
+}
+
+// pubKeyToAddress return the address of the given public key with the given version
+func pubKeyToAddress(pub PubKey, version byte) Address {
+	payload := hash160(pub)
 	payload = append([]byte{version}, payload...)
 	payload = append(payload, checksum(payload)...)
 
@@ -52,6 +86,9 @@ func checksum(payload []byte) []byte {
 // ValidateAddress check if address is valid
 func ValidateAddress(address string) bool {
 	pubHash := DecodeBase58(address)
+	if len(pubHash) <= 1+addressChecksumLen {
+		return false
+	}
 	actualChecksum := pubHash[len(pubHash)-addressChecksumLen:]
 	version := pubHash[0]
 	pubKeyHash := pubHash[1 : len(pubHash)-addressChecksumLen]
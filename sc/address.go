@@ -0,0 +1,238 @@
+package sc
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+)
+
+var errorInvalidAddress = errors.New("error: invalid address")
+
+// AddressTx a transaction which received money to or spent money from an address
+type AddressTx struct {
+	TxID          Hash      `json:"txid"`
+	BlockHash     Hash      `json:"blockhash"`
+	Height        int       `json:"height"`
+	Confirmations int       `json:"confirmations"`
+	Timestamp     time.Time `json:"time"`
+	Received      Amount    `json:"received"`
+	Sent          Amount    `json:"sent"`
+	// Fee the fee of the transaction; only set when the address paid for it
+	Fee Amount `json:"fee"`
+	// Counterparty the address paid to when sending, or the address paying when receiving
+	Counterparty Address `json:"counterparty,omitempty"`
+}
+
+// Net return the amount the transaction added to (positive) or removed from (negative) the address
+func (tx AddressTx) Net() Amount {
+	return tx.Received - tx.Sent
+}
+
+// IsSent return true if the address spent money in the transaction
+func (tx AddressTx) IsSent() bool {
+	return tx.Sent > 0
+}
+
+// HistoryQuery filter and paginate the transaction history. Zero values mean no filter
+type HistoryQuery struct {
+	From   time.Time
+	To     time.Time
+	Offset int
+	Limit  int
+}
+
+// Apply return the page of transactions in the date range, keeping their order
+func (q HistoryQuery) Apply(history []AddressTx) []AddressTx {
+	filtered := make([]AddressTx, 0)
+	for _, tx := range history {
+		if !q.From.IsZero() && tx.Timestamp.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && tx.Timestamp.After(q.To) {
+			continue
+		}
+		filtered = append(filtered, tx)
+	}
+	// negative offsets start at the first transaction and negative limits don't limit
+	offset := q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(filtered) {
+		return []AddressTx{}
+	}
+	filtered = filtered[offset:]
+	if q.Limit > 0 && q.Limit < len(filtered) {
+		filtered = filtered[:q.Limit]
+	}
+	return filtered
+}
+
+// String return the base58 form of the address
+func (a Address) String() string {
+	return string(a)
+}
+
+// PubKeyHash return the public key hash encoded in the address
+func (a Address) PubKeyHash() ([]byte, error) {
+	if !ValidateAddress(string(a)) {
+		return nil, errorInvalidAddress
+	}
+	payload := DecodeBase58(string(a))
+	return payload[1 : len(payload)-addressChecksumLen], nil
+}
+
+// pubKeyHash return the public key hash encoded in an address of the network
+func (bc *Blockchain) pubKeyHash(address Address) ([]byte, error) {
+	if !bc.params.ValidateAddress(string(address)) {
+		return nil, errorInvalidAddress
+	}
+	return address.PubKeyHash()
+}
+
+// pubKeyHashOf return the public key hash the P2PKH script pays to; nil for other scripts
+func pubKeyHashOf(script string) []byte {
+	ops := strings.Fields(script)
+	if len(ops) != 5 || ops[0] != "OP_DUP" || ops[1] != "OP_HASH160" || ops[3] != "OP_EQUALVERIFY" || ops[4] != "OP_CHECKSIG" {
+		return nil
+	}
+	h, err := Address(ops[2]).PubKeyHash()
+	if err != nil {
+		return nil
+	}
+	return h
+}
+
+// BalanceOf return the amount the address can spend and the amount locked in immature coinbase outputs.
+// It doesn't need the private key so it works for any address
+func (bc *Blockchain) BalanceOf(address Address) (available Amount, immature Amount, err error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	h, err := bc.pubKeyHash(address)
+	if err != nil {
+		return 0, 0, err
+	}
+	available, immature, _ = bc.unspentOf(h)
+	return available, immature, nil
+}
+
+// AddressHistory return the transactions which received money to or spent money from the address,
+// from the oldest to the newest. It uses the address index when enabled, otherwise it scans the chain
+func (bc *Blockchain) AddressHistory(address Address) ([]AddressTx, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	h, err := bc.pubKeyHash(address)
+	if err != nil {
+		return nil, err
+	}
+	if bc.HasAddressIndex() {
+		return bc.indexedHistory(h), nil
+	}
+	tip := bc.tipHeight()
+	// all outputs seen so far, so we can tell who spends them
+	outputs := make(map[outPoint]TxOut)
+	prevOut := func(vin TxIn) (TxOut, bool) {
+		out, ok := outputs[outPoint{vin.Txid.String(), vin.Vout}]
+		return out, ok
+	}
+	history := make([]AddressTx, 0)
+	it := bc.chainIterator(0)
+	for b := it.Next(); b != nil; b = it.Next() {
+		height := it.Height()
+		for _, tx := range b.Transactions {
+			record := addressTxOf(h, tx, prevOut)
+			for idx, vout := range tx.Vout {
+				outputs[outPoint{tx.ID.String(), idx}] = vout
+			}
+			if record.Received > 0 || record.Sent > 0 {
+				record.BlockHash = b.CalHash()
+				record.Height = height
+				record.Confirmations = tip - height + 1
+				record.Timestamp = b.Timestamp
+				history = append(history, record)
+			}
+		}
+	}
+	return history, nil
+}
+
+// addressTxOf return how much the transaction received to and spent from the given public key hash.
+// prevOut return the output an input spends. The caller sets where the transaction is in the chain
+func addressTxOf(h []byte, tx *Transaction, prevOut func(TxIn) (TxOut, bool)) AddressTx {
+	record := AddressTx{TxID: tx.ID}
+	inAmount := Amount(0)
+	for _, vin := range tx.Vin {
+		prev, ok := prevOut(vin)
+		if !ok {
+			continue
+		}
+		inAmount += prev.Value
+		if bytes.Equal(pubKeyHashOf(prev.ScriptPubKey), h) {
+			record.Sent += prev.Value
+		} else if record.Counterparty == nil {
+			record.Counterparty = scriptAddress(prev.ScriptPubKey)
+		}
+	}
+	for _, vout := range tx.Vout {
+		if bytes.Equal(pubKeyHashOf(vout.ScriptPubKey), h) {
+			record.Received += vout.Value
+		} else if record.Sent > 0 && record.Counterparty == nil {
+			record.Counterparty = scriptAddress(vout.ScriptPubKey)
+		}
+	}
+	if record.Sent > 0 {
+		record.Fee = inAmount - tx.OutputValue()
+	}
+	return record
+}
+
+// Address return the address the output pays to; nil if it is not a P2PKH output
+func (txOut *TxOut) Address() Address {
+	return scriptAddress(txOut.ScriptPubKey)
+}
+
+// UTXOs return the outputs the address can spend. Immature coinbase outputs are left out
+func (bc *Blockchain) UTXOs(address Address) ([]UTXO, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	h, err := bc.pubKeyHash(address)
+	if err != nil {
+		return nil, err
+	}
+	_, _, utxos := bc.unspentOf(h)
+	return utxos, nil
+}
+
+// scriptAddress return the address the P2PKH script pays to; nil for other scripts
+func scriptAddress(script string) Address {
+	if pubKeyHashOf(script) == nil {
+		return nil
+	}
+	return Address(strings.Fields(script)[2])
+}
+
+// unspentOf collect the unspent outputs paying to the given public key hash. The inputs are not signed.
+// It uses the address index when enabled, otherwise it scans the chain
+func (bc *Blockchain) unspentOf(pubKeyHash []byte) (total Amount, immature Amount, utxos []UTXO) {
+	if bc.HasAddressIndex() {
+		return bc.indexedUnspent(pubKeyHash)
+	}
+	utxos = make([]UTXO, 0)
+	for op, entry := range bc.utxoSet(bc.tipHash()) {
+		if !bytes.Equal(pubKeyHashOf(entry.out.ScriptPubKey), pubKeyHash) {
+			continue
+		}
+		if entry.coinbase && !bc.params.isMature(entry.confirmations) {
+			immature += entry.out.Value
+			continue
+		}
+		utxos = append(utxos, UTXO{
+			TxIn:         TxIn{Txid: StringToHash(op.txid), Vout: op.vout},
+			Value:        entry.out.Value,
+			ScriptPubKey: entry.out.ScriptPubKey,
+		})
+		total += entry.out.Value
+	}
+	return
+}
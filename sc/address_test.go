@@ -0,0 +1,159 @@
+package sc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWatchOnlyAccount(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	w := NewMemWallet(blockchain)
+	w.Add("miner", miner)
+	w.Add("alice", alice)
+
+	// a second wallet only knows the address of alice
+	watcher := NewMemWallet(blockchain)
+	if err := watcher.Watch("alice", testParams.Address(alice)); err != nil {
+		t.Fatalf("failed to watch address: %v", err)
+	}
+	if err := watcher.Watch("bad", Address("not an address")); err != errorInvalidAddress {
+		t.Errorf("expected %v but got %v", errorInvalidAddress, err)
+	}
+
+	blockchain.Mine(1)
+	w.Send("miner", "alice", 3*Coin)
+	w.Send("alice", "miner", 1*Coin)
+
+	assertEquals(t, "alice", 2*Coin, watcher.Balance("alice"))
+	history := watcher.History("alice", HistoryQuery{})
+	if len(history) != 2 {
+		t.Fatalf("expected 2 transactions but got %d", len(history))
+	}
+	assertEquals(t, "sent", 3*Coin, history[0].Sent)
+	assertEquals(t, "change", 2*Coin, history[0].Received)
+	assertEquals(t, "received", 3*Coin, history[1].Received)
+
+	// watch-only accounts cannot spend
+	watcher.Send("alice", "alice", 1*Coin)
+	assertEquals(t, "alice", 2*Coin, watcher.Balance("alice"))
+}
+
+func TestAddressPubKeyHash(t *testing.T) {
+	acc := NewAccount()
+	h, err := testParams.Address(acc).PubKeyHash()
+	if err != nil || !bytes.Equal(h, hash160(acc.PubKey)) {
+		t.Errorf("address should encode the public key hash")
+	}
+	script := (&Blockchain{}).ScriptPubKey(testParams.Address(acc))
+	if !bytes.Equal(pubKeyHashOf(script), h) {
+		t.Errorf("script should pay to the public key hash")
+	}
+	if pubKeyHashOf((&Blockchain{}).ScriptData([]byte("data"))) != nil {
+		t.Errorf("data carrier should not pay to any public key hash")
+	}
+	if _, err := Address("").PubKeyHash(); err != errorInvalidAddress {
+		t.Errorf("expected %v but got %v", errorInvalidAddress, err)
+	}
+}
+
+func TestAccountHistory(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	w := NewMemWallet(blockchain)
+	w.Add("miner", miner)
+	w.Add("alice", alice)
+
+	blockchain.Mine(1)
+	w.SendWithFee("miner", "alice", 3*Coin, 1*Coin)
+	start := time.Now()
+	w.Send("alice", "miner", 1*Coin)
+	blockchain.Mine(2)
+
+	history := w.History("alice", HistoryQuery{})
+	if len(history) != 2 {
+		t.Fatalf("expected 2 transactions but got %d", len(history))
+	}
+	sent, received := history[0], history[1]
+	if !sent.IsSent() || received.IsSent() {
+		t.Errorf("newest transaction should be the payment of alice")
+	}
+	assertEquals(t, "net", -1*Coin, sent.Net())
+	assertEquals(t, "fee", 0, sent.Fee)
+	if !bytes.Equal(sent.Counterparty, testParams.Address(miner)) || !bytes.Equal(received.Counterparty, testParams.Address(miner)) {
+		t.Errorf("counterparty should be the miner")
+	}
+	if sent.Height != 3 || sent.Confirmations != 3 || received.Height != 2 || received.Confirmations != 4 {
+		t.Errorf("unexpected heights or confirmations %+v %+v", sent, received)
+	}
+
+	minerHistory := w.History("miner", HistoryQuery{})
+	payment := minerHistory[len(minerHistory)-3]
+	assertEquals(t, "fee", 1*Coin, payment.Fee)
+	if !bytes.Equal(payment.Counterparty, testParams.Address(alice)) {
+		t.Errorf("counterparty should be alice")
+	}
+
+	// filters and pagination
+	if h := w.History("alice", HistoryQuery{From: start}); len(h) != 1 || !h[0].IsSent() {
+		t.Errorf("expected only the payment after the start time")
+	}
+	if h := w.History("alice", HistoryQuery{To: start}); len(h) != 1 || h[0].IsSent() {
+		t.Errorf("expected only the payment before the start time")
+	}
+	if h := w.History("miner", HistoryQuery{Offset: 1, Limit: 2}); len(h) != 2 || !bytes.Equal(h[0].TxID, minerHistory[1].TxID) {
+		t.Errorf("expected the second page of the history")
+	}
+	if h := w.History("miner", HistoryQuery{Offset: 100}); len(h) != 0 {
+		t.Errorf("expected empty page")
+	}
+	if h := w.History("miner", HistoryQuery{Offset: -1, Limit: -1}); len(h) != len(minerHistory) {
+		t.Errorf("negative offset and limit should return the whole history but got %d", len(h))
+	}
+}
+
+func TestSendToAddress(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	bob := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+	w := NewMemWallet(blockchain)
+	w.Add("miner", miner)
+	before := w.Balance("miner")
+
+	w.Send("miner", string(testParams.Address(alice)), 1*Coin)
+	if err := w.AddContact("bob", testParams.Address(bob)); err != nil {
+		t.Fatal(err)
+	}
+	w.Send("miner", "bob", 2*Coin)
+	available, _, _ := blockchain.BalanceOf(testParams.Address(alice))
+	assertEquals(t, "alice", available, 1*Coin)
+	available, _, _ = blockchain.BalanceOf(testParams.Address(bob))
+	assertEquals(t, "bob", available, 2*Coin)
+
+	// a typo in the address breaks its checksum
+	bad := []byte(testParams.Address(alice))
+	if bad[5] == 'a' {
+		bad[5] = 'b'
+	} else {
+		bad[5] = 'a'
+	}
+	if err := w.AddContact("carol", Address(bad)); err != errorInvalidAddress {
+		t.Errorf("expected invalid address but got %v", err)
+	}
+	w.Send("miner", string(bad), 1*Coin)
+	// only the two payments above were mined
+	assertEquals(t, "miner", w.Balance("miner"), before+2*testParams.Subsidy(1)-3*Coin)
+
+	w.RemoveContact("bob")
+	if len(w.Contacts()) != 0 {
+		t.Errorf("expected an empty address book")
+	}
+}
@@ -0,0 +1,240 @@
+package sc
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// addrIndexKey is set when the address index is enabled
+var addrIndexKey = []byte("addrindex")
+
+// the address index stores every output with its spent flag, and for every public key hash
+// the transactions which paid to it or spent from it
+var txoPrefix = []byte("txo-")
+var addrTxsPrefix = []byte("addr-")
+
+// txoRecord an output of the active chain and whether it is spent
+type txoRecord struct {
+	Out      TxOut
+	Coinbase bool
+	Spent    bool
+}
+
+// addrTxRef a transaction touching an address and where it is in the chain
+type addrTxRef struct {
+	TxID      Hash
+	BlockHash Hash
+	Pos       int
+	Height    int
+}
+
+func txoKey(txid Hash, vout int) []byte {
+	key := append(append([]byte{}, txoPrefix...), txid...)
+	return binary.BigEndian.AppendUint32(key, uint32(vout))
+}
+
+func addrTxsKey(pubKeyHash []byte) []byte {
+	return append(append([]byte{}, addrTxsPrefix...), pubKeyHash...)
+}
+
+// HasAddressIndex return true if the outputs and transactions are indexed by address
+func (bc *Blockchain) HasAddressIndex() bool {
+	ok, _ := bc.db.Has(addrIndexKey)
+	return ok
+}
+
+// EnableAddressIndex index the outputs and transactions of the chain by address and keep the index
+// up to date as blocks are added, so balance and history queries don't scan the chain.
+// The setting is stored in the database
+func (bc *Blockchain) EnableAddressIndex() {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	if bc.HasAddressIndex() {
+		return
+	}
+	bc.rebuildAddressIndex()
+}
+
+// RebuildAddressIndex index all outputs and transactions of the active chain, e.g. for a database
+// created before the index was enabled
+func (bc *Blockchain) RebuildAddressIndex() {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	bc.rebuildAddressIndex()
+}
+
+func (bc *Blockchain) rebuildAddressIndex() {
+	// drop what an earlier index holds for the addresses of the chain, including stale references,
+	// so transactions are not recorded twice. Blocks leaving the chain were already disconnected
+	it := bc.chainIterator(0)
+	for b := it.Next(); b != nil; b = it.Next() {
+		for _, tx := range b.Transactions {
+			for idx, vout := range tx.Vout {
+				bc.db.Delete(txoKey(tx.ID, idx))
+				if h := pubKeyHashOf(vout.ScriptPubKey); h != nil {
+					bc.db.Delete(addrTxsKey(h))
+				}
+			}
+		}
+	}
+	it = bc.chainIterator(0)
+	for b := it.Next(); b != nil; b = it.Next() {
+		bc.connectAddresses(b, it.Height())
+	}
+	bc.db.Put(addrIndexKey, []byte{1})
+}
+
+// connectAddresses add the outputs of the block, mark the outputs it spends and record
+// its transactions for every address they touch
+func (bc *Blockchain) connectAddresses(b *Block, height int) {
+	hash := b.CalHash()
+	for pos, tx := range b.Transactions {
+		touched := make([][]byte, 0)
+		for _, vin := range tx.Vin {
+			if vin.IsCoinBase() {
+				continue
+			}
+			rec, ok := bc.getTxo(vin.Txid, vin.Vout)
+			if !ok {
+				continue
+			}
+			rec.Spent = true
+			bc.db.Put(txoKey(vin.Txid, vin.Vout), toBytes(rec))
+			touched = append(touched, pubKeyHashOf(rec.Out.ScriptPubKey))
+		}
+		for idx, vout := range tx.Vout {
+			bc.db.Put(txoKey(tx.ID, idx), toBytes(txoRecord{Out: vout, Coinbase: tx.IsCoinBase()}))
+			touched = append(touched, pubKeyHashOf(vout.ScriptPubKey))
+		}
+		ref := addrTxRef{TxID: tx.ID, BlockHash: hash, Pos: pos, Height: height}
+		for _, h := range touched {
+			if h == nil {
+				continue
+			}
+			refs := bc.getAddrTxs(h)
+			if len(refs) > 0 && bytes.Equal(refs[len(refs)-1].TxID, tx.ID) {
+				continue
+			}
+			bc.db.Put(addrTxsKey(h), toBytes(append(refs, ref)))
+		}
+	}
+}
+
+// disconnectAddresses undo connectAddresses for a block leaving the active chain
+func (bc *Blockchain) disconnectAddresses(b *Block) {
+	for i := len(b.Transactions) - 1; i >= 0; i-- {
+		tx := b.Transactions[i]
+		touched := make([][]byte, 0)
+		for idx, vout := range tx.Vout {
+			bc.db.Delete(txoKey(tx.ID, idx))
+			touched = append(touched, pubKeyHashOf(vout.ScriptPubKey))
+		}
+		for _, vin := range tx.Vin {
+			if vin.IsCoinBase() {
+				continue
+			}
+			rec, ok := bc.getTxo(vin.Txid, vin.Vout)
+			if !ok {
+				continue
+			}
+			rec.Spent = false
+			bc.db.Put(txoKey(vin.Txid, vin.Vout), toBytes(rec))
+			touched = append(touched, pubKeyHashOf(rec.Out.ScriptPubKey))
+		}
+		for _, h := range touched {
+			if h == nil {
+				continue
+			}
+			refs := bc.getAddrTxs(h)
+			if len(refs) > 0 && bytes.Equal(refs[len(refs)-1].TxID, tx.ID) {
+				bc.db.Put(addrTxsKey(h), toBytes(refs[:len(refs)-1]))
+			}
+		}
+	}
+}
+
+func (bc *Blockchain) getTxo(txid Hash, vout int) (txoRecord, bool) {
+	var rec txoRecord
+	v, err := bc.db.Get(txoKey(txid, vout))
+	if err != nil || toObject(v, &rec) != nil {
+		return rec, false
+	}
+	return rec, true
+}
+
+func (bc *Blockchain) getAddrTxs(pubKeyHash []byte) []addrTxRef {
+	refs := make([]addrTxRef, 0)
+	v, err := bc.db.Get(addrTxsKey(pubKeyHash))
+	if err != nil {
+		return refs
+	}
+	toObject(v, &refs)
+	return refs
+}
+
+// indexedTransaction return the transaction the reference points to; nil if the index is stale
+func (bc *Blockchain) indexedTransaction(ref addrTxRef) (*Block, *Transaction) {
+	b := bc.getBlock(ref.BlockHash)
+	if b == nil || ref.Pos < 0 || ref.Pos >= len(b.Transactions) || !bytes.Equal(b.Transactions[ref.Pos].ID, ref.TxID) {
+		return nil, nil
+	}
+	return b, b.Transactions[ref.Pos]
+}
+
+// indexedUnspent collect the unspent outputs paying to the public key hash using the address index
+func (bc *Blockchain) indexedUnspent(pubKeyHash []byte) (total Amount, immature Amount, utxos []UTXO) {
+	utxos = make([]UTXO, 0)
+	tip := bc.tipHeight()
+	for _, ref := range bc.getAddrTxs(pubKeyHash) {
+		_, tx := bc.indexedTransaction(ref)
+		if tx == nil {
+			continue
+		}
+		for idx, vout := range tx.Vout {
+			if !bytes.Equal(pubKeyHashOf(vout.ScriptPubKey), pubKeyHash) {
+				continue
+			}
+			rec, ok := bc.getTxo(tx.ID, idx)
+			if !ok || rec.Spent {
+				continue
+			}
+			if rec.Coinbase && !bc.params.isMature(tip-ref.Height+1) {
+				immature += vout.Value
+				continue
+			}
+			utxos = append(utxos, UTXO{
+				TxIn:         TxIn{Txid: tx.ID, Vout: idx},
+				Value:        vout.Value,
+				ScriptPubKey: vout.ScriptPubKey,
+			})
+			total += vout.Value
+		}
+	}
+	return
+}
+
+// indexedHistory return the history of the public key hash using the address index
+func (bc *Blockchain) indexedHistory(pubKeyHash []byte) []AddressTx {
+	prevOut := func(vin TxIn) (TxOut, bool) {
+		if vin.IsCoinBase() {
+			return TxOut{}, false
+		}
+		rec, ok := bc.getTxo(vin.Txid, vin.Vout)
+		return rec.Out, ok
+	}
+	tip := bc.tipHeight()
+	history := make([]AddressTx, 0)
+	for _, ref := range bc.getAddrTxs(pubKeyHash) {
+		b, tx := bc.indexedTransaction(ref)
+		if tx == nil {
+			continue
+		}
+		record := addressTxOf(pubKeyHash, tx, prevOut)
+		record.BlockHash = ref.BlockHash
+		record.Height = ref.Height
+		record.Confirmations = tip - ref.Height + 1
+		record.Timestamp = b.Timestamp
+		history = append(history, record)
+	}
+	return history
+}
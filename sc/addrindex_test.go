@@ -0,0 +1,65 @@
+package sc
+
+import (
+	"reflect"
+	"testing"
+)
+
+// scanned return the balance and history of the address computed by scanning the chain
+func scanned(bc *Blockchain, address Address) (Amount, []AddressTx) {
+	bc.db.Delete(addrIndexKey)
+	defer bc.db.Put(addrIndexKey, []byte{1})
+	balance, _, _ := bc.BalanceOf(address)
+	history, _ := bc.AddressHistory(address)
+	return balance, history
+}
+
+func assertIndexMatchesScan(t *testing.T, bc *Blockchain, accounts ...*Account) {
+	for _, acc := range accounts {
+		balance, _, _ := bc.BalanceOf(testParams.Address(acc))
+		history, _ := bc.AddressHistory(testParams.Address(acc))
+		scanBalance, scanHistory := scanned(bc, testParams.Address(acc))
+		assertEquals(t, "balance", balance, scanBalance)
+		if !reflect.DeepEqual(history, scanHistory) {
+			t.Errorf("indexed history %+v doesn't match scanned history %+v", history, scanHistory)
+		}
+	}
+}
+
+func TestAddressIndex(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	bob := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+	blockchain.SendWithFee(miner, testParams.Address(alice), 3*Coin, Coin)
+
+	// an existing database gets indexed when the index is enabled
+	blockchain.EnableAddressIndex()
+	assertIndexMatchesScan(t, blockchain, miner, alice, bob)
+
+	blockchain.Send(alice, testParams.Address(bob), 2*Coin)
+	blockchain.Send(bob, testParams.Address(alice), Coin)
+	assertIndexMatchesScan(t, blockchain, miner, alice, bob)
+	if history, _ := blockchain.AddressHistory(testParams.Address(alice)); len(history) != 3 {
+		t.Errorf("expected 3 transactions for alice but got %d", len(history))
+	}
+
+	// a longer branch on top of block 2 replaces the payments between alice and bob
+	mineFork(blockchain, miner, 2, blockchain.Height()-1)
+	assertIndexMatchesScan(t, blockchain, miner, alice, bob)
+	available, _, _ := blockchain.BalanceOf(testParams.Address(alice))
+	assertEquals(t, "alice", available, 3*Coin)
+
+	// stale references are skipped and dropped by a rebuild
+	stale := addrTxRef{TxID: hash256([]byte("gone")), BlockHash: hash256([]byte("gone")), Pos: 3}
+	refs := blockchain.getAddrTxs(hash160(alice.PubKey))
+	db.Put(addrTxsKey(hash160(alice.PubKey)), toBytes(append(refs, stale)))
+	assertIndexMatchesScan(t, blockchain, miner, alice, bob)
+	blockchain.RebuildAddressIndex()
+	assertIndexMatchesScan(t, blockchain, miner, alice, bob)
+	if n := len(blockchain.getAddrTxs(hash160(alice.PubKey))); n != len(refs) {
+		t.Errorf("rebuild should drop the stale reference but got %d references", n)
+	}
+}
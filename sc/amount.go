@@ -0,0 +1,110 @@
+package sc
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Amount an amount of money in shatoshi, the smallest unit of the coin
+type Amount int64
+
+const (
+	// Shatoshi the smallest unit of the coin
+	Shatoshi Amount = 1
+	// Coin number of shatoshi in one coin
+	Coin Amount = 100000000
+	// MaxAmount no amount can be larger than the total number of coins ever created
+	MaxAmount = 21000000 * Coin
+)
+
+const coinDecimals = 8
+const coinSymbol = "$C"
+
+var errorAmountOutOfRange = errors.New("error: amount is negative or larger than the maximum amount")
+var errorInvalidAmount = errors.New("error: invalid amount")
+
+// IsValid return true if the amount is in the range [0, MaxAmount]
+func (a Amount) IsValid() bool {
+	return a >= 0 && a <= MaxAmount
+}
+
+// Add return the sum of the amounts; it fails if either amount or the sum is out of range
+func (a Amount) Add(b Amount) (Amount, error) {
+	if !a.IsValid() || !b.IsValid() || a > MaxAmount-b {
+		return 0, errorAmountOutOfRange
+	}
+	return a + b, nil
+}
+
+// Sub return the difference of the amounts; it fails if either amount or the difference is out of range
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if !a.IsValid() || !b.IsValid() || b > a {
+		return 0, errorAmountOutOfRange
+	}
+	return a - b, nil
+}
+
+// String format the amount in coins, e.g. 1.5 $C
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	whole := strconv.FormatInt(v/int64(Coin), 10)
+	frac := strings.TrimRight(strconv.FormatInt(int64(Coin)+v%int64(Coin), 10)[1:], "0")
+	if frac != "" {
+		whole += "." + frac
+	}
+	return sign + whole + " " + coinSymbol
+}
+
+// MarshalJSON encode the amount as a number of coins like 1.5
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strings.TrimSuffix(a.String(), " "+coinSymbol)), nil
+}
+
+// UnmarshalJSON decode a number of coins like 1.5 or "1.5"; negative amounts are accepted
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	v, err := ParseAmount(strings.TrimPrefix(s, "-"))
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(s, "-") {
+		v = -v
+	}
+	*a = v
+	return nil
+}
+
+// ParseAmount parse a human readable amount of coins like "1.5" or "1.5 $C"
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), coinSymbol))
+	parts := strings.Split(s, ".")
+	if len(parts) > 2 || parts[0] == "" {
+		return 0, errorInvalidAmount
+	}
+	whole, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || whole > uint64(MaxAmount/Coin) {
+		return 0, errorInvalidAmount
+	}
+	a := Amount(whole) * Coin
+	if len(parts) == 2 {
+		frac := parts[1]
+		if frac == "" || len(frac) > coinDecimals {
+			return 0, errorInvalidAmount
+		}
+		v, err := strconv.ParseUint(frac+strings.Repeat("0", coinDecimals-len(frac)), 10, 64)
+		if err != nil {
+			return 0, errorInvalidAmount
+		}
+		a += Amount(v)
+	}
+	if !a.IsValid() {
+		return 0, errorAmountOutOfRange
+	}
+	return a, nil
+}
@@ -0,0 +1,69 @@
+package sc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAmountArithmetic(t *testing.T) {
+	if v, err := Coin.Add(Shatoshi); err != nil || v != 100000001 {
+		t.Errorf("expected 100000001 but got %d, %v", v, err)
+	}
+	if _, err := MaxAmount.Add(Shatoshi); err != errorAmountOutOfRange {
+		t.Errorf("adding beyond the maximum amount should fail")
+	}
+	if _, err := Amount(-1).Add(Coin); err != errorAmountOutOfRange {
+		t.Errorf("adding a negative amount should fail")
+	}
+	if _, err := Shatoshi.Sub(Coin); err != errorAmountOutOfRange {
+		t.Errorf("subtracting to a negative amount should fail")
+	}
+}
+
+func TestAmountFormatting(t *testing.T) {
+	cases := []struct {
+		s string
+		a Amount
+	}{
+		{"0 $C", 0},
+		{"1 $C", Coin},
+		{"1.5 $C", Coin + Coin/2},
+		{"0.00000001 $C", Shatoshi},
+		{"21000000 $C", MaxAmount},
+	}
+	for _, c := range cases {
+		if c.a.String() != c.s {
+			t.Errorf("expected %s but got %s", c.s, c.a.String())
+		}
+		a, err := ParseAmount(c.s)
+		if err != nil || a != c.a {
+			t.Errorf("parsing %s: expected %d but got %d, %v", c.s, c.a, a, err)
+		}
+	}
+	for _, s := range []string{"", "abc", "-1", "1.", ".5", "1.123456789", "21000001", "1.2.3"} {
+		if _, err := ParseAmount(s); err == nil {
+			t.Errorf("parsing %q should fail", s)
+		}
+	}
+	if a, err := ParseAmount("2.5"); err != nil || a != 250000000 {
+		t.Errorf("expected 250000000 but got %d, %v", a, err)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	for _, a := range []Amount{0, 1, Coin + Coin/2, -Coin / 4, MaxAmount} {
+		data, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Amount
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		assertEquals(t, string(data), decoded, a)
+	}
+	var a Amount
+	if err := json.Unmarshal([]byte(`"2.5"`), &a); err != nil || a != 2*Coin+Coin/2 {
+		t.Errorf("expected 2.5 $C but got %s, %v", a, err)
+	}
+}
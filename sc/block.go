@@ -2,10 +2,21 @@ package sc
 
 import (
 	"bytes"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
+var errorInvalidPoW = errors.New("error: invalid proof of work")
+var errorWrongDifficulty = errors.New("error: block difficulty doesn't match the network")
+var errorTimeTooNew = errors.New("error: block timestamp is too far in the future")
+var errorNoCoinbase = errors.New("error: first transaction of the block is not a coinbase")
+var errorMultipleCoinbases = errors.New("error: block has more than one coinbase")
+var errorTooManyTxs = errors.New("error: block has too many transactions")
+var errorBlockTooLarge = errors.New("error: block is larger than the maximum block size")
+
 // Block prepresent a block in the blockchain
 type Block struct {
 	Timestamp  time.Time
@@ -51,15 +62,40 @@ func (block *Block) CalHash() Hash {
 	return hash256(b)
 }
 
-// newBlock mine a new block with the given transactions
-func newBlock(transactions []*Transaction, prevHash Hash) *Block {
-	b := &Block{
-		Transactions: transactions,
-		PrevHash:     prevHash,
-		Timestamp:    time.Now(),
-		Difficulty:   difficulty,
+// CheckSanity check the block is well formed under the rules of the network without looking at the chain
+func (block *Block) CheckSanity(params *ChainParams) error {
+	// validate proof of work
+	if block.Difficulty != params.Difficulty {
+		return errorWrongDifficulty
+	}
+	prefix := strings.Repeat("0", block.Difficulty)
+	if !strings.HasPrefix(hex.EncodeToString(block.CalHash()), prefix) {
+		return errorInvalidPoW
+	}
+	if block.Timestamp.After(time.Now().Add(params.MaxFutureBlockTime)) {
+		return errorTimeTooNew
+	}
+	if len(block.Transactions) == 0 {
+		return errorNoCoinbase
+	}
+	if len(block.Transactions) > params.MaxBlockTransactions {
+		return errorTooManyTxs
+	}
+	if len(toBytes(block)) > params.MaxBlockSize {
+		return errorBlockTooLarge
+	}
+	if !block.Transactions[0].IsCoinBase() {
+		return errorNoCoinbase
+	}
+	for idx, tx := range block.Transactions {
+		if idx > 0 && tx.IsCoinBase() {
+			return errorMultipleCoinbases
+		}
+		if err := tx.CheckSanity(params); err != nil {
+			return err
+		}
 	}
-	return b
+	return nil
 }
 
 // IsGenesis return if this block is genesis block
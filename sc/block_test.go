@@ -0,0 +1,91 @@
+package sc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testScript a valid output script for the sanity checks
+var testScript = fmt.Sprintf(scriptPubKey, testParams.Address(NewAccount()))
+
+func TestTransactionSanity(t *testing.T) {
+	in := TxIn{Txid: hash256([]byte("tx")), Vout: 0}
+	cases := []struct {
+		name string
+		tx   *Transaction
+		err  error
+	}{
+		{"valid", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: 1, ScriptPubKey: testScript}}}, nil},
+		{"no inputs", &Transaction{Vout: []TxOut{{Value: 1, ScriptPubKey: testScript}}}, errorNoInputs},
+		{"no outputs", &Transaction{Vin: []TxIn{in}}, errorNoOutputs},
+		{"negative value", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: -1, ScriptPubKey: testScript}}}, errorValueOutOfRange},
+		{"too large value", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: MaxAmount + 1, ScriptPubKey: testScript}}}, errorValueOutOfRange},
+		{"overflow", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: MaxAmount, ScriptPubKey: testScript}, {Value: 1, ScriptPubKey: testScript}}}, errorValueOutOfRange},
+		{"duplicate inputs", &Transaction{Vin: []TxIn{in, in}, Vout: []TxOut{{Value: 1, ScriptPubKey: testScript}}}, errorDuplicateInput},
+		{"coinbase input", &Transaction{Vin: []TxIn{in, {Vout: -1}}, Vout: []TxOut{{Value: 1, ScriptPubKey: testScript}}}, errorInvalidInput},
+		{"data carrier", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: 0, ScriptPubKey: "OP_RETURN 0a0b"}}}, nil},
+		{"empty script", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: 1}}}, errorInvalidScript},
+		{"short address", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: 1, ScriptPubKey: fmt.Sprintf(scriptPubKey, "1a")}}}, errorInvalidScript},
+		{"missing opcode", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: 1, ScriptPubKey: "OP_EQUALVERIFY OP_CHECKSIG"}}}, errorInvalidScript},
+		{"invalid data", &Transaction{Vin: []TxIn{in}, Vout: []TxOut{{Value: 0, ScriptPubKey: "OP_RETURN xyz"}}}, errorInvalidScript},
+	}
+	for _, c := range cases {
+		if err := c.tx.CheckSanity(testParams); err != c.err {
+			t.Errorf("%s: expected %v but got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestBlockSanity(t *testing.T) {
+	coinbase := func() *Transaction { return NewCoinbase(testScript, 1) }
+	tx := &Transaction{Vin: []TxIn{{Txid: hash256([]byte("tx")), Vout: 0}}, Vout: []TxOut{{Value: 1, ScriptPubKey: testScript}}}
+	cases := []struct {
+		name string
+		txs  []*Transaction
+		ts   time.Time
+		err  error
+	}{
+		{"valid", []*Transaction{coinbase(), tx}, time.Now(), nil},
+		{"empty", []*Transaction{}, time.Now(), errorNoCoinbase},
+		{"no coinbase", []*Transaction{tx}, time.Now(), errorNoCoinbase},
+		{"two coinbases", []*Transaction{coinbase(), coinbase()}, time.Now(), errorMultipleCoinbases},
+		{"future", []*Transaction{coinbase()}, time.Now().Add(3 * time.Hour), errorTimeTooNew},
+	}
+	for _, c := range cases {
+		b := testParams.newBlock(c.txs, Hash{})
+		b.Timestamp = c.ts
+		b.Nonce = testParams.PoWer.Work(b)
+		if err := b.CheckSanity(testParams); err != c.err {
+			t.Errorf("%s: expected %v but got %v", c.name, c.err, err)
+		}
+	}
+
+	b := testParams.newBlock([]*Transaction{coinbase()}, Hash{})
+	b.Nonce = testParams.PoWer.Work(b) + 1
+	for b.CheckSanity(testParams) == nil {
+		b.Nonce++
+	}
+	if err := b.CheckSanity(testParams); err != errorInvalidPoW {
+		t.Errorf("expected %v but got %v", errorInvalidPoW, err)
+	}
+}
+
+func TestBlockMedianTime(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(3)
+
+	b := mineBlock(blockchain, miner)
+	b.Timestamp = blockchain.medianTime(blockchain.tipHash())
+	b.Nonce = testParams.PoWer.Work(b)
+	if err := blockchain.validateBlock(b); err != errorTimeTooOld {
+		t.Errorf("expected %v but got %v", errorTimeTooOld, err)
+	}
+	b.Timestamp = time.Now()
+	b.Nonce = testParams.PoWer.Work(b)
+	if err := blockchain.validateBlock(b); err != nil {
+		t.Errorf("expected valid block but got %v", err)
+	}
+}
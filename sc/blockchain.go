@@ -9,85 +9,316 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
-var difficulty = 2
-var reward = 5
 var sigLen = 64
 var scriptPubKey = "OP_DUP OP_HASH160 %s OP_EQUALVERIFY OP_CHECKSIG"
+var scriptData = "OP_RETURN %s"
 var errorNotHisMoney = errors.New("error: this guy is trying to spend money of someone else")
 var errorNotEnoughMoney = errors.New("error: this guy is trying spend more that what he has")
-var shatoshiNakamotoAddress = Address("1NHXs8UxcgHzDNxWNTcYjKv8MGY72rnbbE")
+var errorDataTooLarge = errors.New("error: data carrier output is larger than allowed")
+var errorImmatureCoinbase = errors.New("error: this guy is trying to spend a coinbase which is not mature yet")
+var errorInvalidCoinbase = errors.New("error: coinbase claims more than the subsidy plus fees")
+var errorUnknownParent = errors.New("error: parent block not found")
+var errorTimeTooOld = errors.New("error: block timestamp is not after the median time of the previous blocks")
+var errorBlockNotFound = errors.New("error: block not found")
+var errorTxNotFound = errors.New("error: transaction not found")
+var errorBlockFull = errors.New("error: transaction doesn't fit in the block")
 
-var poWer PoWer = NewSimPow()
-
-// Blockchain the main chain
+// Blockchain the main chain. It is safe for concurrent use: blocks are added under the write lock
+// while the queries hold the read lock
 type Blockchain struct {
-	db    Database
-	miner *Account
+	lock   sync.RWMutex
+	params *ChainParams
+	db     Database
+	miner  *Account
+	events *eventBus
 }
 
-// NewBlockchain return a new blockchain with genesis block inside
-func NewBlockchain(miner *Account, db Database) *Blockchain {
-	bc := &Blockchain{
-		db:    db,
-		miner: miner,
+// NewBlockchain return a new blockchain of the network with the given parameters with genesis block inside.
+// It panics if the parameters are invalid or the database holds the chain of another network
+func NewBlockchain(params *ChainParams, miner *Account, db Database) *Blockchain {
+	bc, err := OpenBlockchain(params, miner, db)
+	if err != nil {
+		panic(err)
 	}
-	bc.addGenesisBlock()
 	return bc
 }
 
-func (bc *Blockchain) addGenesisBlock() {
+// OpenBlockchain return the blockchain of the network stored in the database, starting with
+// the genesis block if the database is empty. It fails if the parameters are invalid or the stored
+// genesis is not the one of the network
+func OpenBlockchain(params *ChainParams, miner *Account, db Database) (*Blockchain, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	bc := &Blockchain{
+		params: params,
+		db:     db,
+		miner:  miner,
+		events: newEventBus(),
+	}
+	if err := bc.addGenesisBlock(); err != nil {
+		return nil, err
+	}
+	return bc, nil
+}
+
+// addGenesisBlock add the genesis block of the network to an empty database, or check the
+// database starts with it
+func (bc *Blockchain) addGenesisBlock() error {
+	genesis := bc.params.GenesisBlock()
+	if !bytes.Equal(genesis.CalHash(), bc.params.GenesisHash) {
+		return errorWrongGenesis
+	}
+	// the genesis follows the rules of the network like any other block
+	if err := bc.validateBlock(genesis); err != nil {
+		return err
+	}
 	if lb, _ := bc.db.Get(lastBlockKey); len(lb) == 0 {
-		b := newBlock([]*Transaction{NewCoinbase(bc.ScriptPubKey(shatoshiNakamotoAddress))}, Hash{})
-		b.Nonce = poWer.Work(b)
-		bc.addBlock(b)
+		return bc.addBlock(genesis)
+	}
+	if stored, ok := bc.activeHash(0); !ok || !bytes.Equal(stored, bc.params.GenesisHash) {
+		return errorWrongGenesis
 	}
+	return nil
 }
 
-// SetPoWer set the PoWer
-func SetPoWer(power PoWer) {
-	poWer = power
+// Params return the parameters of the network the blockchain follows
+func (bc *Blockchain) Params() *ChainParams {
+	return bc.params
 }
 
-func (bc *Blockchain) addBlock(b *Block) {
-	if !bc.isValidBlock(b) {
-		fmt.Println("error: fake block....")
-		return
+// addBlock validate the block and add it to the chain. The caller holds the write lock
+func (bc *Blockchain) addBlock(b *Block) error {
+	if err := bc.validateBlock(b); err != nil {
+		return err
 	}
-	bc.db.Put(b.CalHash(), toBytes(b))
-	bc.db.Put(lastBlockKey, toBytes(b))
+	disconnected, connected := bc.connectBlock(b)
+	bc.updateIndexes(disconnected, connected)
+	bc.publishBlocks(disconnected, connected)
+	return nil
 }
 
 // isValidBlock validate the block is valid
 func (bc *Blockchain) isValidBlock(block *Block) bool {
-	// validate proof of work
-	prefix := strings.Repeat("0", block.Difficulty)
-	hashValue := hex.EncodeToString(block.CalHash())
-	if !strings.HasPrefix(hashValue, prefix) {
-		fmt.Println("error: invalid proof of work")
-		return false
+	return bc.validateBlock(block) == nil
+}
+
+// validateBlock check the block and all its transactions are valid on top of its parent
+func (bc *Blockchain) validateBlock(block *Block) error {
+	if err := block.CheckSanity(bc.params); err != nil {
+		return err
 	}
 
 	// ignore other validations if it is the genesis
 	if block.IsGenesis() {
-		return true
+		if !bytes.Equal(block.CalHash(), bc.params.GenesisHash) {
+			return errorWrongGenesis
+		}
+		return nil
 	}
 	// verify its parent
 	prevBlock := bc.getBlock(block.PrevHash)
-	if bytes.Compare(block.PrevHash, prevBlock.CalHash()) != 0 {
-		return false
+	if prevBlock == nil || bytes.Compare(block.PrevHash, prevBlock.CalHash()) != 0 {
+		return errorUnknownParent
 	}
-	// verify the transactions are valid; don't need to validate the coinbase
+	if !block.Timestamp.After(bc.medianTime(block.PrevHash)) {
+		return errorTimeTooOld
+	}
+	// verify the transactions are valid against the outputs unspent at its parent and
+	// the transactions before them in the block; don't need to validate the coinbase
+	view := bc.utxoSet(block.PrevHash)
+	view.apply(block.Transactions[0])
+	fees := Amount(0)
 	for _, tx := range block.Transactions[1:] {
-		if err := bc.validateTransaction(tx); err != nil {
-			fmt.Println("error: invalid transaction")
+		fee, err := view.validateTransaction(tx, bc.params)
+		if err != nil {
 			tx.Print()
-			return false
+			return err
 		}
+		view.apply(tx)
+		if fees, err = fees.Add(fee); err != nil {
+			return err
+		}
+	}
+	// the miner can only claim the subsidy and the fees
+	if block.Transactions[0].OutputValue() > bc.params.Subsidy(bc.heightOf(block.PrevHash)+1)+fees {
+		return errorInvalidCoinbase
+	}
+	return nil
+}
+
+// medianTime return the median timestamp of the last blocks up to the block with the given hash
+func (bc *Blockchain) medianTime(hash Hash) time.Time {
+	timestamps := make([]time.Time, 0)
+	it := NewBlockIteratorFrom(bc.db, hash)
+	for b := it.Next(); b != nil && len(timestamps) < bc.params.MedianTimeSpan; b = it.Next() {
+		timestamps = append(timestamps, b.Timestamp)
 	}
-	return true
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Before(timestamps[j])
+	})
+	return timestamps[len(timestamps)/2]
+}
+
+// heightOf return the height of the block with the given hash from the block index;
+// the genesis is at height 0 and unknown blocks at -1
+func (bc *Blockchain) heightOf(hash Hash) int {
+	height, _ := bc.storedHeight(hash)
+	return height
+}
+
+// Height return the height of the last block of the chain; the genesis block is at height 0
+func (bc *Blockchain) Height() int {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	return bc.tipHeight()
+}
+
+// BestBlockHash return hash of the last block of the chain
+func (bc *Blockchain) BestBlockHash() Hash {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	return bc.tipHash()
+}
+
+// Tip return the height and the hash of the last block of the chain, read at the same time
+func (bc *Blockchain) Tip() (int, Hash) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	return bc.tipHeight(), bc.tipHash()
+}
+
+// GetBlockByHash return the block with the given hash
+func (bc *Blockchain) GetBlockByHash(hash Hash) (*Block, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	return bc.blockByHash(hash)
+}
+
+func (bc *Blockchain) blockByHash(hash Hash) (*Block, error) {
+	b := bc.getBlock(hash)
+	if b == nil || len(hash) == 0 {
+		return nil, errorBlockNotFound
+	}
+	return b, nil
+}
+
+// GetBlockByHeight return the block of the active chain at the given height
+func (bc *Blockchain) GetBlockByHeight(height int) (*Block, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	return bc.blockByHeight(height)
+}
+
+func (bc *Blockchain) blockByHeight(height int) (*Block, error) {
+	hash, ok := bc.activeHash(height)
+	if !ok {
+		return nil, errorBlockNotFound
+	}
+	return bc.blockByHash(hash)
+}
+
+// BlockHeight return the height of the block with the given hash
+func (bc *Blockchain) BlockHeight(hash Hash) (int, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	height, ok := bc.storedHeight(hash)
+	if !ok {
+		return -1, errorBlockNotFound
+	}
+	return height, nil
+}
+
+// Confirmations return how many blocks of the active chain confirm the block with the given hash,
+// counting the block itself, or -1 if the block is not on the active chain
+func (bc *Blockchain) Confirmations(hash Hash) (int, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	height, ok := bc.storedHeight(hash)
+	if !ok {
+		return 0, errorBlockNotFound
+	}
+	if active, ok := bc.activeHash(height); !ok || !bytes.Equal(active, hash) {
+		return -1, nil
+	}
+	return bc.tipHeight() - height + 1, nil
+}
+
+// GetTransaction return the confirmed transaction with the given id and the block including it.
+// It uses the transaction index when enabled, otherwise it scans the chain from the tip
+func (bc *Blockchain) GetTransaction(txid Hash) (*TxInfo, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	if bc.HasTxIndex() {
+		return bc.lookupTransaction(txid)
+	}
+	tip := bc.tipHeight()
+	for height := tip; height >= 0; height-- {
+		b, err := bc.blockByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range b.Transactions {
+			if bytes.Equal(tx.ID, txid) {
+				return &TxInfo{
+					Tx:            tx,
+					BlockHash:     b.CalHash(),
+					Height:        height,
+					Confirmations: tip - height + 1,
+				}, nil
+			}
+		}
+	}
+	return nil, errorTxNotFound
+}
+
+// GetTransactions return the confirmed transactions with the given ids by their id string; the ones
+// not found are left out. Without the transaction index the chain is scanned once from the tip
+func (bc *Blockchain) GetTransactions(txids []Hash) map[string]*TxInfo {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	found := make(map[string]*TxInfo)
+	if bc.HasTxIndex() {
+		for _, txid := range txids {
+			if info, err := bc.lookupTransaction(txid); err == nil {
+				found[txid.String()] = info
+			}
+		}
+		return found
+	}
+	wanted := make(map[string]bool)
+	for _, txid := range txids {
+		wanted[txid.String()] = true
+	}
+	tip := bc.tipHeight()
+	for height := tip; height >= 0 && len(found) < len(wanted); height-- {
+		b, err := bc.blockByHeight(height)
+		if err != nil {
+			break
+		}
+		for _, tx := range b.Transactions {
+			if wanted[tx.ID.String()] {
+				found[tx.ID.String()] = &TxInfo{
+					Tx:            tx,
+					BlockHash:     b.CalHash(),
+					Height:        height,
+					Confirmations: tip - height + 1,
+				}
+			}
+		}
+	}
+	return found
+}
+
+// tipHash return hash of the last block of the chain
+func (bc *Blockchain) tipHash() Hash {
+	return bc.getBlock(lastBlockKey).CalHash()
 }
 
 func (bc *Blockchain) getBlock(key []byte) *Block {
@@ -100,156 +331,302 @@ func (bc *Blockchain) getBlock(key []byte) *Block {
 	return b
 }
 
-// MineNewBlock add a new block into the blockchain
-func (bc *Blockchain) MineNewBlock(transactions []*Transaction) {
+// MineNewBlock add a new block into the blockchain. The transactions which can't be included are
+// left out and the block is mined with the others; the error of the first one left out is returned
+func (bc *Blockchain) MineNewBlock(transactions []*Transaction) error {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
 	var prevBlock *Block
 	v, _ := bc.db.Get(lastBlockKey)
 	toObject(v, &prevBlock)
-	validTxs := bc.validTransactions(transactions)
-	// add reward for mining a block
-	txs := []*Transaction{NewCoinbase(bc.ScriptPubKey(bc.miner.GetAddress()))}
+	validTxs, fees, rejected := bc.selectTransactions(bc.utxoSet(prevBlock.CalHash()), transactions)
+	// add subsidy and fees for mining a block
+	reward, err := bc.params.Subsidy(bc.heightOf(prevBlock.CalHash()) + 1).Add(fees)
+	if err != nil {
+		return err
+	}
+	txs := []*Transaction{NewCoinbase(bc.ScriptPubKey(bc.params.Address(bc.miner)), reward)}
 	txs = append(txs, validTxs...)
-	b := newBlock(txs, prevBlock.CalHash())
-	b.Nonce = poWer.Work(b)
-	bc.addBlock(b)
+	b := bc.params.newBlock(txs, prevBlock.CalHash())
+	b.Nonce = bc.params.PoWer.Work(b)
+	if err := bc.addBlock(b); err != nil {
+		return err
+	}
+	return rejected
 }
 
-// Spendable return total amount up to the given amount and prepare list transaction input for spending
-func (bc *Blockchain) Spendable(acc *Account, amount int) (total int, spendable []TxIn) {
-	spent := make(map[string][]bool)
-	txs := make(map[string]*Transaction)
-	it := NewBlockIterator(bc.db)
-	for b := it.Next(); b != nil; b = it.Next() {
-		for _, tx := range b.Transactions {
-			txs[tx.ID.String()] = tx
-			spent[tx.ID.String()] = make([]bool, len(tx.Vout))
-		}
+// Balance return the amount the account can spend and the amount locked in immature coinbase outputs
+func (bc *Blockchain) Balance(acc *Account) (available Amount, immature Amount) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	available, immature, _ = bc.unspent(acc)
+	return
+}
+
+// unspent collect the unspent outputs belong to the given account
+func (bc *Blockchain) unspent(acc *Account) (total Amount, immature Amount, utxos []UTXO) {
+	return bc.unspentOf(hash160(acc.PubKey))
+}
+
+// Send sending money from an account to an address
+func (bc *Blockchain) Send(from *Account, to Address, amount Amount) {
+	bc.SendWithFee(from, to, amount, 0)
+}
+
+// SendWithFee sending money from an account to an address and pay the given fee to the miner
+func (bc *Blockchain) SendWithFee(from *Account, to Address, amount Amount, fee Amount) {
+	bc.SendUsing(from, to, amount, fee, nil)
+}
+
+// SendUsing sending money from an account to an address, picking the inputs with the given
+// coin selector, or spending the largest outputs first if nil. It return how many inputs were spent and the change
+func (bc *Blockchain) SendUsing(from *Account, to Address, amount Amount, fee Amount, selector CoinSelector) SelectionStats {
+	if !bc.params.ValidateAddress(string(to)) {
+		panic(errorInvalidAddress)
 	}
-	// mark all spent transaction output...
-	it = NewBlockIterator(bc.db)
-	for b := it.Next(); b != nil; b = it.Next() {
-		for _, tx := range b.Transactions {
-			for _, vin := range tx.Vin {
-				if !vin.IsCoinBase() {
-					spent[vin.Txid.String()][vin.Vout] = true
-				}
-			}
-		}
+	if amount <= 0 || !amount.IsValid() || !fee.IsValid() {
+		panic(errorAmountOutOfRange)
 	}
-	utxos := make([]TxOut, 0)
-	spendableTxIns := make([]TxIn, 0)
-	for txid, spt := range spent {
-		for idx, alreadySpent := range spt {
-			if !alreadySpent {
-				utxos = append(utxos, txs[txid].Vout[idx])
-				spendableTxIns = append(spendableTxIns, TxIn{
-					Txid: StringToHash(txid),
-					Vout: idx,
-				})
-			}
-		}
+	vouts := []TxOut{
+		TxOut{
+			Value:        amount,
+			ScriptPubKey: bc.ScriptPubKey(to),
+		},
 	}
-	spendable = make([]TxIn, 0)
-	total = 0
-	for idx, txout := range utxos {
-		if verifyOwnership(bc.ScriptSig(acc), txout.ScriptPubKey) {
-			txInV := spendableTxIns[idx]
-			txInV.ScriptSig = bc.ScriptSig(acc)
-			spendable = append(spendable, txInV)
-			// if amount == -1 {
-			// 	total += txout.Value
-			// } else {
-			// 	if total >= amount {
-			// 		break
-			// 	}
-			// 	total += txout.Value
-			// }
-			total += txout.Value
-		}
+	tx, stats, err := bc.newTransactionFrom([]*Account{from}, changeTo(from), amount, fee, vouts, selector)
+	if err != nil {
+		panic(err)
 	}
-	return
+	if err := bc.MineNewBlock([]*Transaction{tx}); err != nil {
+		panic(err)
+	}
+	return stats
 }
 
-// Send sending money from an address to another address
-func (bc *Blockchain) Send(from *Account, to *Account, amount int) {
-	total, spendableTxIns := bc.Spendable(from, amount)
-	if total < amount {
-		panic(errorNotEnoughMoney)
-	}
-	vins := make([]TxIn, 0)
-	for _, vin := range spendableTxIns {
-		newvin := vin
-		newvin.ScriptSig = bc.ScriptSig(from)
-		vins = append(vins, newvin)
+// PublishData anchor the given data on the chain using an unspendable OP_RETURN output
+func (bc *Blockchain) PublishData(from *Account, data []byte) *Transaction {
+	if len(data) > bc.params.MaxDataCarrierSize {
+		panic(errorDataTooLarge)
 	}
 	vouts := []TxOut{
 		TxOut{
-			Value:        amount,
-			ScriptPubKey: bc.ScriptPubKey(to.GetAddress()),
+			Value:        0,
+			ScriptPubKey: bc.ScriptData(data),
 		},
 	}
+	tx := bc.newTransaction(from, 0, 0, vouts)
+	if err := bc.MineNewBlock([]*Transaction{tx}); err != nil {
+		panic(err)
+	}
+	return tx
+}
+
+// newTransaction spend the money of the given account to pay the given amount to the outputs
+// and the fee to the miner. The change is sent back to the owner
+func (bc *Blockchain) newTransaction(from *Account, amount Amount, fee Amount, vouts []TxOut) *Transaction {
+	tx, _, err := bc.newTransactionFrom([]*Account{from}, changeTo(from), amount, fee, vouts, nil)
+	if err != nil {
+		panic(err)
+	}
+	return tx
+}
+
+// changeTo return a change source sending the change back to the given account
+func changeTo(acc *Account) func() (*Account, error) {
+	return func() (*Account, error) {
+		return acc, nil
+	}
+}
+
+// newTransactionFrom spend the money of the given accounts to pay the given amount to the outputs
+// and the fee to the miner. The inputs are picked by the given coin selector, or the largest first if nil.
+// The change is sent to the account returned by change, which is only called if there is change
+func (bc *Blockchain) newTransactionFrom(from []*Account, change func() (*Account, error), amount Amount, fee Amount, vouts []TxOut, selector CoinSelector) (*Transaction, SelectionStats, error) {
+	required, err := amount.Add(fee)
+	if err != nil {
+		return nil, SelectionStats{}, err
+	}
+	if selector == nil {
+		selector = LargestFirst{}
+	}
+	utxos := make([]UTXO, 0)
+	owners := make(map[outPoint]ownedOutput)
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	for _, acc := range from {
+		_, _, accUTXOs := bc.unspent(acc)
+		for _, u := range accUTXOs {
+			owners[outPoint{u.TxIn.Txid.String(), u.TxIn.Vout}] = ownedOutput{acc: acc, out: TxOut{Value: u.Value, ScriptPubKey: u.ScriptPubKey}}
+		}
+		utxos = append(utxos, accUTXOs...)
+	}
+	selected, err := selector.Select(utxos, required)
+	if err != nil {
+		return nil, SelectionStats{}, err
+	}
+	stats := newSelectionStats(selected, required)
+	if stats.Total < required || stats.Inputs == 0 {
+		return nil, SelectionStats{}, errorNotEnoughMoney
+	}
+	vins := make([]TxIn, 0, len(selected))
+	for _, u := range selected {
+		vins = append(vins, u.TxIn)
+	}
 	// sending change to the owner
-	if total > amount {
+	if stats.Change > 0 {
+		acc, err := change()
+		if err != nil {
+			return nil, SelectionStats{}, err
+		}
 		vouts = append(vouts, TxOut{
-			Value:        total - amount,
-			ScriptPubKey: bc.ScriptPubKey(from.GetAddress()),
+			Value:        stats.Change,
+			ScriptPubKey: bc.ScriptPubKey(bc.params.Address(acc)),
 		})
 	}
 	tx := &Transaction{
 		Vin:  vins,
 		Vout: vouts,
 	}
+	if err := bc.signInputs(tx, owners); err != nil {
+		return nil, SelectionStats{}, err
+	}
+	return tx, stats, nil
+}
+
+// ownedOutput an unspent output and the account which can spend it
+type ownedOutput struct {
+	acc *Account
+	out TxOut
+}
+
+// signInputs sign every input of the transaction with the key of the account owning the output
+// it spends. The transaction gets a new ID
+func (bc *Blockchain) signInputs(tx *Transaction, owners map[outPoint]ownedOutput) error {
+	for i, vin := range tx.Vin {
+		owner, ok := owners[outPoint{vin.Txid.String(), vin.Vout}]
+		if !ok {
+			return errorUnknownInput
+		}
+		tx.Vin[i].ScriptSig = bc.ScriptSig(owner.acc, tx, i, owner.out)
+	}
 	tx.SetID()
-	bc.MineNewBlock([]*Transaction{tx})
+	return nil
 }
 
-// validateTransaction go over the block chain and check if the input can unlock the output it refers to
-//TODO should cache the unspent tractions output somewhere...don't need to scan entire the blockchain for this...
-func (bc *Blockchain) validateTransaction(tx *Transaction) error {
-	// check if vin can be unlocked
-	inAmount := 0
+// FindData return the block and the transaction which carry the given data
+func (bc *Blockchain) FindData(data []byte) (*Block, *Transaction) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
 	it := NewBlockIterator(bc.db)
 	for b := it.Next(); b != nil; b = it.Next() {
-		for _, tran := range b.Transactions {
-			for _, vin := range tx.Vin {
-				if bytes.Compare(tran.ID, vin.Txid) == 0 {
-					vout := tran.Vout[vin.Vout]
-					if !vin.CanUnlock(vout) {
-						return errorNotHisMoney
-					}
-					inAmount += vout.Value
+		for _, tx := range b.Transactions {
+			for _, vout := range tx.Vout {
+				if vout.IsDataCarrier() && bytes.Compare(vout.Data(), data) == 0 {
+					return b, tx
 				}
 			}
 		}
 	}
-	// check if the total amount in >= out amount...
-	outAmount := 0
-	for _, vout := range tx.Vout {
-		outAmount += vout.Value
-		if outAmount > inAmount {
-			return errorNotEnoughMoney
+	return nil, nil
+}
+
+// usedScripts return the scripts of all outputs in the chain
+func (bc *Blockchain) usedScripts() map[string]bool {
+	used := make(map[string]bool)
+	it := NewBlockIterator(bc.db)
+	for b := it.Next(); b != nil; b = it.Next() {
+		for _, tx := range b.Transactions {
+			for _, vout := range tx.Vout {
+				used[vout.ScriptPubKey] = true
+			}
 		}
 	}
-	return nil
+	return used
 }
 
-// validTransactions return valid transaction and ignore others
-func (bc *Blockchain) validTransactions(trans []*Transaction) []*Transaction {
-	txs := make([]*Transaction, 0)
+// validateTransaction check if the transaction can be added on top of the current chain.
+// It returns the fee of the transaction which is the total input minus the total output
+func (bc *Blockchain) validateTransaction(tx *Transaction) (Amount, error) {
+	return bc.utxoSet(bc.tipHash()).validateTransaction(tx, bc.params)
+}
+
+// Fee return the fee the given transaction pays to the miner
+func (bc *Blockchain) Fee(tx *Transaction) (Amount, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	return bc.validateTransaction(tx)
+}
+
+// FeeRate return the fee per byte the given transaction pays to the miner
+func (bc *Blockchain) FeeRate(tx *Transaction) (float64, error) {
+	fee, err := bc.Fee(tx)
+	if err != nil {
+		return 0, err
+	}
+	return float64(fee) / float64(tx.Size()), nil
+}
+
+// selectTransactions pick the valid transactions paying the highest fee rate first until the block is full.
+// Transactions conflicting with the ones already picked are ignored.
+// It returns the selected transactions, the total fees they pay and the error of the first transaction left out
+func (bc *Blockchain) selectTransactions(view utxoView, trans []*Transaction) ([]*Transaction, Amount, error) {
+	type candidate struct {
+		tx   *Transaction
+		fee  Amount
+		rate float64
+	}
+	var rejected error
+	reject := func(tx *Transaction, err error) {
+		bc.events.publish(Event{Type: TransactionRejected, Tx: tx, Err: err})
+		if rejected == nil {
+			rejected = err
+		}
+	}
+	candidates := make([]candidate, 0)
 	for _, tx := range trans {
-		if err := bc.validateTransaction(tx); err != nil {
+		fee, err := view.validateTransaction(tx, bc.params)
+		if err != nil {
 			fmt.Println("error: invalid transaction", err)
-		} else {
-			txs = append(txs, tx)
+			reject(tx, err)
+			continue
+		}
+		candidates = append(candidates, candidate{tx: tx, fee: fee, rate: float64(fee) / float64(tx.Size())})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].rate > candidates[j].rate
+	})
+	// leave room for the header and the coinbase
+	size := len(toBytes(Block{})) + NewCoinbase(bc.ScriptPubKey(bc.params.Address(bc.miner)), bc.params.InitialSubsidy).Size()
+	txs := make([]*Transaction, 0)
+	fees := Amount(0)
+	for _, c := range candidates {
+		if size+c.tx.Size() > bc.params.MaxBlockSize {
+			reject(c.tx, errorBlockFull)
+			continue
+		}
+		if _, err := view.validateTransaction(c.tx, bc.params); err != nil {
+			fmt.Println("error: conflicting transaction", err)
+			reject(c.tx, err)
+			continue
 		}
+		total, err := fees.Add(c.fee)
+		if err != nil {
+			reject(c.tx, err)
+			continue
+		}
+		view.apply(c.tx)
+		size += c.tx.Size()
+		fees = total
+		txs = append(txs, c.tx)
 	}
-	return txs
+	return txs, fees, rejected
 }
 
-// ScriptSig return scriptSig for unlocking coin
-func (bc *Blockchain) ScriptSig(acc *Account) []byte {
-	r, s, _ := ecdsa.Sign(rand.Reader, &acc.PriKey, hash160(acc.PubKey))
-	sig := append(r.Bytes(), s.Bytes()...)
+// ScriptSig return scriptSig for unlocking the output prevOut with the input idx of the transaction.
+// The signature only holds for this transaction, so it can't be replayed to spend other coins
+func (bc *Blockchain) ScriptSig(acc *Account, tx *Transaction, idx int, prevOut TxOut) []byte {
+	r, s, _ := ecdsa.Sign(rand.Reader, &acc.PriKey, tx.sigHash(idx, prevOut))
+	sig := append(r.FillBytes(make([]byte, sigLen/2)), s.FillBytes(make([]byte, sigLen/2))...)
 	scriptSig := append(sig, acc.PubKey...)
 	return scriptSig[:]
 }
@@ -259,14 +636,25 @@ func (bc *Blockchain) ScriptPubKey(address Address) string {
 	return fmt.Sprintf(scriptPubKey, address)
 }
 
-// verifyOwnership execute P2PKH script: OP_DUP OP_HASH160 <pub key hash> OP_EQUALVERIFY OP_CHECKSIG
-func verifyOwnership(scriptSig []byte, scriptPubKey string) bool {
+// ScriptData return OP_RETURN script for carrying data
+func (bc *Blockchain) ScriptData(data []byte) string {
+	return fmt.Sprintf(scriptData, hex.EncodeToString(data))
+}
+
+// verifyOwnership execute P2PKH script: OP_DUP OP_HASH160 <pub key hash> OP_EQUALVERIFY OP_CHECKSIG.
+// OP_CHECKSIG verifies the signature of the given digest
+func verifyOwnership(scriptSig []byte, scriptPubKey string, digest Hash) bool {
+	// unsigned or malformed inputs
+	if len(scriptSig) <= sigLen {
+		return false
+	}
 	sig := scriptSig[:sigLen]
 	pubKey := scriptSig[sigLen:]
 	stack := &Stack{Values: make([][]byte, 0)}
 	stack.Push(sig)
 	stack.Push(pubKey)
 	ops := strings.Fields(scriptPubKey)
+	checked := false
 	for _, op := range ops {
 		if op == "OP_DUP" {
 			stack.Push(stack.Peak())
@@ -274,6 +662,10 @@ func verifyOwnership(scriptSig []byte, scriptPubKey string) bool {
 			v := stack.Pop()
 			stack.Push(hash160(v))
 		} else if op == "OP_EQUALVERIFY" {
+			// malformed scripts can't pop more values than the scriptSig pushed
+			if stack.Len() < 2 {
+				return false
+			}
 			v1 := stack.Pop()
 			v2 := stack.Pop()
 			if bytes.Compare(v1, v2) != 0 {
@@ -288,23 +680,33 @@ func verifyOwnership(scriptSig []byte, scriptPubKey string) bool {
 			var y big.Int
 			y.SetBytes(pubKey[len(pubKey)/2:])
 			pubkey := ecdsa.PublicKey{Curve: elliptic.P256(), X: &x, Y: &y}
-			ok := ecdsa.Verify(&pubkey, hash160(pubKey), &r, &s)
+			ok := ecdsa.Verify(&pubkey, digest, &r, &s)
 			if !ok {
 				return false
 			}
+			checked = true
+		} else if op == "OP_RETURN" { // provably unspendable
+			return false
 		} else { // the address
+			if !ValidateAddress(op) {
+				return false
+			}
 			address := DecodeBase58(op)
 			stack.Push(address[1 : len(address)-addressChecksumLen])
 		}
 	}
-	return true
+	// a script which doesn't check any signature can't be spent by anyone
+	return checked
 }
 
 // Mine start mining blocks to get reward...
-func (bc *Blockchain) Mine(n int) {
+func (bc *Blockchain) Mine(n int) error {
 	for i := 0; i < n; i++ {
-		bc.MineNewBlock([]*Transaction{})
+		if err := bc.MineNewBlock([]*Transaction{}); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // PrintTransactions print all tractions that happen in the past
@@ -327,10 +729,12 @@ func (bc *Blockchain) Print() {
 
 //Validate validate if the blockchain is valid
 func (bc *Blockchain) Validate() error {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
 	it := NewBlockIterator(bc.db)
 	for b := it.Next(); b != nil; b = it.Next() {
-		if !bc.isValidBlock(b) {
-			return errors.New("error: invalid blockchain")
+		if err := bc.validateBlock(b); err != nil {
+			return fmt.Errorf("error: invalid blockchain: %v", err)
 		}
 	}
 	return nil
@@ -1,30 +1,58 @@
 package sc
 
 import (
+	"bytes"
 	"testing"
 )
 
+// testParams the regtest rules without coinbase maturity since most tests spend their mining rewards right away
+var testParams = func() *ChainParams {
+	params := RegTest()
+	params.CoinbaseMaturity = 0
+	return params
+}()
+
 func TestVerifyOwnership(t *testing.T) {
 	miner := NewAccount()
 	db, _ := NewMemDatabase()
-	blockchain := NewBlockchain(miner, db)
+	blockchain := NewBlockchain(testParams, miner, db)
 
 	if err := blockchain.Validate(); err != nil {
 		t.Errorf("invalid blockchain\n")
 	}
 
-	sig := blockchain.ScriptSig(miner)
-	scriptPubKey := blockchain.ScriptPubKey(miner.GetAddress())
+	scriptPubKey := blockchain.ScriptPubKey(testParams.Address(miner))
+	prevOut := TxOut{Value: 5 * Coin, ScriptPubKey: scriptPubKey}
+	tx := &Transaction{
+		Vin:  []TxIn{{Txid: hash256([]byte("coin")), Vout: 0}},
+		Vout: []TxOut{{Value: 5 * Coin, ScriptPubKey: scriptPubKey}},
+	}
+	sig := blockchain.ScriptSig(miner, tx, 0, prevOut)
+	digest := tx.sigHash(0, prevOut)
 
-	if !verifyOwnership(sig, scriptPubKey) {
+	if !verifyOwnership(sig, scriptPubKey, digest) {
 		t.Errorf("failed to verify ownership")
 	}
+	// the signature only holds for the signed transaction
+	other := &Transaction{
+		Vin:  []TxIn{{Txid: hash256([]byte("other coin")), Vout: 0}},
+		Vout: tx.Vout,
+	}
+	if verifyOwnership(sig, scriptPubKey, other.sigHash(0, prevOut)) {
+		t.Errorf("signature should not unlock another transaction")
+	}
+	// malformed scripts are refused instead of crashing
+	for _, script := range []string{"", "OP_EQUALVERIFY OP_EQUALVERIFY OP_EQUALVERIFY", "OP_DUP OP_HASH160 1a OP_EQUALVERIFY OP_CHECKSIG", "OP_DUP OP_HASH160"} {
+		if verifyOwnership(sig, script, digest) {
+			t.Errorf("script %q should not be spendable", script)
+		}
+	}
 }
 
 func TestTransactions(t *testing.T) {
 	miner := NewAccount()
 	db, _ := NewMemDatabase()
-	blockchain := NewBlockchain(miner, db)
+	blockchain := NewBlockchain(testParams, miner, db)
 
 	if err := blockchain.Validate(); err != nil {
 		t.Errorf("invalid blockchain\n")
@@ -35,21 +63,193 @@ func TestTransactions(t *testing.T) {
 	w.Add("bob", NewAccount())
 
 	blockchain.Mine(5)
-	assertEquals(t, "miner", 25, w.Balance("miner"))
-	assertEquals(t, "bob", 0, w.Balance("bob"))
-	assertEquals(t, "alice", 0, w.Balance("alice"))
+	assertEquals(t, "miner", 25*Coin, w.Balance("miner"))
+	assertEquals(t, "bob", 0*Coin, w.Balance("bob"))
+	assertEquals(t, "alice", 0*Coin, w.Balance("alice"))
 
-	w.Send("miner", "alice", 2)
-	w.Send("miner", "bob", 2)
-	w.Send("alice", "bob", 1)
+	w.Send("miner", "alice", 2*Coin)
+	w.Send("miner", "bob", 2*Coin)
+	w.Send("alice", "bob", 1*Coin)
 
-	assertEquals(t, "miner", 36, w.Balance("miner"))
-	assertEquals(t, "bob", 3, w.Balance("bob"))
-	assertEquals(t, "alice", 1, w.Balance("alice"))
+	assertEquals(t, "miner", 36*Coin, w.Balance("miner"))
+	assertEquals(t, "bob", 3*Coin, w.Balance("bob"))
+	assertEquals(t, "alice", 1*Coin, w.Balance("alice"))
 }
 
-func assertEquals(t *testing.T, acc1 string, v1, v2 int) {
+func assertEquals(t *testing.T, acc1 string, v1, v2 Amount) {
 	if v1 != v2 {
-		t.Errorf("balance of %s should be %d but got %d\n", acc1, v1, v2)
+		t.Errorf("balance of %s should be %s but got %s\n", acc1, v1, v2)
+	}
+}
+
+func TestDataCarrier(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	w := NewMemWallet(blockchain)
+	w.Add("miner", miner)
+
+	blockchain.Mine(1)
+	h := w.Publish("miner", []byte("my document"))
+
+	b, tx := blockchain.FindData(h)
+	if b == nil || tx == nil {
+		t.Fatalf("published data not found")
+	}
+	if err := blockchain.Validate(); err != nil {
+		t.Errorf("invalid blockchain\n")
+	}
+	// publishing costs nothing and the data output is never spendable
+	assertEquals(t, "miner", 10*Coin, w.Balance("miner"))
+	if _, tx := blockchain.FindData([]byte("unknown")); tx != nil {
+		t.Errorf("should not find unknown data")
+	}
+	// the data output stays out of the unspent outputs
+	view := blockchain.utxoSet(blockchain.tipHash())
+	if _, ok := view[outPoint{tx.ID.String(), 0}]; ok {
+		t.Errorf("data carrier output should not be in the utxo set")
+	}
+	view.apply(tx)
+	if _, ok := view[outPoint{tx.ID.String(), 0}]; ok {
+		t.Errorf("data carrier output should not be added to the utxo set")
+	}
+
+	// the coinbase can't carry more data than any other transaction
+	coinbase := NewCoinbase(blockchain.ScriptPubKey(testParams.Address(miner)), testParams.Subsidy(blockchain.Height()+1))
+	coinbase.Vout = append(coinbase.Vout, TxOut{ScriptPubKey: blockchain.ScriptData(make([]byte, testParams.MaxDataCarrierSize+1))})
+	coinbase.SetID()
+	block := testParams.newBlock([]*Transaction{coinbase}, blockchain.tipHash())
+	block.Nonce = testParams.PoWer.Work(block)
+	if err := blockchain.validateBlock(block); err != errorDataTooLarge {
+		t.Errorf("expected %v but got %v", errorDataTooLarge, err)
+	}
+}
+
+func TestFees(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	w := NewMemWallet(blockchain)
+	w.Add("miner", miner)
+	w.Add("alice", NewAccount())
+	w.Add("bob", NewAccount())
+
+	blockchain.Mine(1)
+	w.SendWithFee("miner", "alice", 2*Coin, 1*Coin)
+	w.SendWithFee("alice", "bob", 1*Coin, 1*Coin)
+
+	if err := blockchain.Validate(); err != nil {
+		t.Errorf("invalid blockchain\n")
+	}
+	// the miner gets the fees back through the coinbase
+	assertEquals(t, "miner", 14*Coin, w.Balance("miner"))
+	assertEquals(t, "alice", 0*Coin, w.Balance("alice"))
+	assertEquals(t, "bob", 1*Coin, w.Balance("bob"))
+}
+
+func TestBlockAssemblyByFeeRate(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	w := NewMemWallet(blockchain)
+	w.Add("miner", miner)
+	w.Add("alice", alice)
+
+	blockchain.Mine(1)
+	w.Send("miner", "alice", 4*Coin)
+
+	cheap := blockchain.newTransaction(miner, Coin, 1*Coin, []TxOut{{Value: Coin, ScriptPubKey: blockchain.ScriptPubKey(testParams.Address(alice))}})
+	expensive := blockchain.newTransaction(alice, Coin, 3*Coin, []TxOut{{Value: Coin, ScriptPubKey: blockchain.ScriptPubKey(testParams.Address(miner))}})
+	blockchain.MineNewBlock([]*Transaction{cheap, expensive})
+
+	b := blockchain.getBlock(lastBlockKey)
+	if len(b.Transactions) != 3 {
+		t.Fatalf("block should contain the coinbase and 2 transactions but got %d", len(b.Transactions))
+	}
+	if bytes.Compare(b.Transactions[1].ID, expensive.ID) != 0 {
+		t.Errorf("transaction with higher fee rate should come first")
+	}
+	assertEquals(t, "coinbase", testParams.Subsidy(2)+4*Coin, b.Transactions[0].OutputValue())
+}
+
+func TestCoinbaseClaimingTooMuch(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+
+	prev := blockchain.getBlock(lastBlockKey)
+	b := testParams.newBlock([]*Transaction{NewCoinbase(blockchain.ScriptPubKey(testParams.Address(miner)), testParams.Subsidy(1)+1)}, prev.CalHash())
+	b.Nonce = testParams.PoWer.Work(b)
+	if blockchain.isValidBlock(b) {
+		t.Errorf("block claiming more than the reward should be rejected")
+	}
+	sub := blockchain.Subscribe(8)
+	if err := blockchain.addBlock(b); err != errorInvalidCoinbase {
+		t.Errorf("expected invalid coinbase but got %v", err)
+	}
+	if len(sub.C) != 0 || !bytes.Equal(blockchain.BestBlockHash(), prev.CalHash()) {
+		t.Errorf("a rejected block should not change the chain")
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.EnableTxIndex()
+	blockchain.EnableAddressIndex()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			blockchain.Mine(1)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			if h := blockchain.Height(); h != 20 {
+				t.Errorf("expected height 20 but got %d", h)
+			}
+			return
+		default:
+		}
+		tip := blockchain.Height()
+		if b, err := blockchain.GetBlockByHeight(tip); err != nil {
+			t.Fatalf("tip %d should be readable: %v", tip, err)
+		} else if _, err := blockchain.GetTransaction(b.Transactions[0].ID); err != nil {
+			t.Fatalf("tip coinbase should be indexed: %v", err)
+		}
+		blockchain.BalanceOf(testParams.Address(miner))
+	}
+}
+
+func TestCoinbaseMaturity(t *testing.T) {
+	params := *testParams
+	params.CoinbaseMaturity = 3
+
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(&params, miner, db)
+	w := NewMemWallet(blockchain)
+	w.Add("miner", miner)
+
+	blockchain.Mine(1)
+	assertEquals(t, "miner", 0*Coin, w.Balance("miner"))
+	assertEquals(t, "miner immature", 5*Coin, w.ImmatureBalance("miner"))
+
+	coinbase := blockchain.getBlock(lastBlockKey).Transactions[0]
+	tx := spend(blockchain, miner, miner, 5*Coin, TxIn{Txid: coinbase.ID, Vout: 0})
+	if _, err := blockchain.validateTransaction(tx); err != errorImmatureCoinbase {
+		t.Errorf("spending an immature coinbase should be rejected but got %v", err)
+	}
+
+	blockchain.Mine(2)
+	assertEquals(t, "miner", 5*Coin, w.Balance("miner"))
+	assertEquals(t, "miner immature", 10*Coin, w.ImmatureBalance("miner"))
+	if _, err := blockchain.validateTransaction(tx); err != nil {
+		t.Errorf("spending a mature coinbase should be accepted but got %v", err)
 	}
 }
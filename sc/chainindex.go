@@ -0,0 +1,133 @@
+package sc
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// the block index stores the height of every block, and the active chain maps every height
+// to the hash of its block
+var blockHeightPrefix = []byte("blockheight-")
+var activeHashPrefix = []byte("activehash-")
+
+func blockHeightKey(hash Hash) []byte {
+	return append(append([]byte{}, blockHeightPrefix...), hash...)
+}
+
+func activeHashKey(height int) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, activeHashPrefix...), uint64(height))
+}
+
+// storedHeight return the height of the block with the given hash from the block index
+func (bc *Blockchain) storedHeight(hash Hash) (int, bool) {
+	v, err := bc.db.Get(blockHeightKey(hash))
+	if err != nil || len(v) != 8 {
+		return -1, false
+	}
+	return int(binary.BigEndian.Uint64(v)), true
+}
+
+// activeHash return the hash of the block at the given height of the active chain
+func (bc *Blockchain) activeHash(height int) (Hash, bool) {
+	v, err := bc.db.Get(activeHashKey(height))
+	if err != nil || len(v) == 0 {
+		return nil, false
+	}
+	return v, true
+}
+
+// tipHeight return the height of the last block; -1 if the chain is empty
+func (bc *Blockchain) tipHeight() int {
+	tip := bc.getBlock(lastBlockKey)
+	if tip == nil {
+		return -1
+	}
+	height, _ := bc.storedHeight(tip.CalHash())
+	return height
+}
+
+// connectBlock store the block with its height and make it the tip of the active chain when its
+// branch is longer than the active chain; otherwise the block is only stored.
+// It return the blocks which left the active chain, from the old tip down, and the blocks which
+// joined it, from the fork up to the new tip. Both are empty when the tip doesn't change
+func (bc *Blockchain) connectBlock(b *Block) (disconnected []*Block, connected []*Block) {
+	hash := b.CalHash()
+	height := 0
+	if !b.IsGenesis() {
+		height = bc.heightOf(b.PrevHash) + 1
+	}
+	oldTip := bc.tipHeight()
+	bc.db.Put(hash, toBytes(b))
+	bc.db.Put(blockHeightKey(hash), binary.BigEndian.AppendUint64(nil, uint64(height)))
+	// the first branch seen keeps the tip until another one gets longer
+	if height <= oldTip {
+		return []*Block{}, []*Block{}
+	}
+
+	// walk the new chain back until it joins the active chain
+	connected = []*Block{b}
+	fork := height - 1
+	for prev := b.PrevHash; fork >= 0; fork-- {
+		if active, ok := bc.activeHash(fork); ok && bytes.Equal(active, prev) {
+			break
+		}
+		pb := bc.getBlock(prev)
+		connected = append([]*Block{pb}, connected...)
+		prev = pb.PrevHash
+	}
+	disconnected = make([]*Block, 0)
+	for h := oldTip; h > fork; h-- {
+		if active, ok := bc.activeHash(h); ok {
+			disconnected = append(disconnected, bc.getBlock(active))
+		}
+		bc.db.Delete(activeHashKey(h))
+	}
+	for i, cb := range connected {
+		bc.db.Put(activeHashKey(fork+1+i), cb.CalHash())
+	}
+	bc.db.Put(lastBlockKey, toBytes(b))
+	return disconnected, connected
+}
+
+// ChainIterator walk the active chain forward from a given height to the tip
+type ChainIterator struct {
+	bc     *Blockchain
+	height int
+	locked bool
+}
+
+// NewChainIterator return an iterator walking the active chain forward from the given height
+func (bc *Blockchain) NewChainIterator(from int) *ChainIterator {
+	return &ChainIterator{
+		bc:     bc,
+		height: from,
+		locked: true,
+	}
+}
+
+// chainIterator return an iterator for callers already holding the lock of the chain
+func (bc *Blockchain) chainIterator(from int) *ChainIterator {
+	return &ChainIterator{
+		bc:     bc,
+		height: from,
+	}
+}
+
+// Next return the next block, or nil after the tip
+func (it *ChainIterator) Next() *Block {
+	if it.locked {
+		it.bc.lock.RLock()
+		defer it.bc.lock.RUnlock()
+	}
+	b, err := it.bc.blockByHeight(it.height)
+	if err != nil {
+		return nil
+	}
+	it.height++
+	return b
+}
+
+// Height return the height of the block the last call to Next returned
+func (it *ChainIterator) Height() int {
+	return it.height - 1
+}
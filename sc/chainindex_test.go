@@ -0,0 +1,117 @@
+package sc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHeightIndex(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(3)
+
+	if h := blockchain.Height(); h != 3 {
+		t.Fatalf("expected height 3 but got %d", h)
+	}
+	it := blockchain.NewChainIterator(0)
+	prev := Hash{}
+	for b := it.Next(); b != nil; b = it.Next() {
+		if !bytes.Equal(b.PrevHash, prev) {
+			t.Errorf("block %d doesn't follow the previous block", it.Height())
+		}
+		hash := b.CalHash()
+		if h, _ := blockchain.BlockHeight(hash); h != it.Height() {
+			t.Errorf("expected height %d but got %d", it.Height(), h)
+		}
+		prev = hash
+	}
+	if it.Height() != 3 || !bytes.Equal(prev, blockchain.BestBlockHash()) {
+		t.Errorf("forward iteration should end at the tip")
+	}
+	if _, err := blockchain.GetBlockByHeight(4); err != errorBlockNotFound {
+		t.Errorf("expected block not found but got %v", err)
+	}
+}
+
+// forkBlock mine a block paying the miner on top of the given parent without adding it
+func forkBlock(bc *Blockchain, miner *Account, parent *Block) *Block {
+	height := bc.heightOf(parent.CalHash()) + 1
+	b := testParams.newBlock([]*Transaction{NewCoinbase(bc.ScriptPubKey(testParams.Address(miner)), testParams.Subsidy(height))}, parent.CalHash())
+	b.Nonce = testParams.PoWer.Work(b)
+	return b
+}
+
+// mineFork add n blocks on top of the block at the given height of the active chain
+func mineFork(bc *Blockchain, miner *Account, height int, n int) []*Block {
+	parent, _ := bc.GetBlockByHeight(height)
+	blocks := make([]*Block, 0, n)
+	for i := 0; i < n; i++ {
+		parent = forkBlock(bc, miner, parent)
+		bc.addBlock(parent)
+		blocks = append(blocks, parent)
+	}
+	return blocks
+}
+
+func TestHeightIndexFollowsNewTip(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(3)
+	old2, _ := blockchain.GetBlockByHeight(2)
+	old3, _ := blockchain.GetBlockByHeight(3)
+	parent, _ := blockchain.GetBlockByHeight(1)
+
+	// a shorter branch and then an equally long branch don't move the tip
+	fork2 := forkBlock(blockchain, miner, parent)
+	fork3 := forkBlock(blockchain, miner, fork2)
+	for _, b := range []*Block{fork2, fork3} {
+		if err := blockchain.validateBlock(b); err != nil {
+			t.Fatal(err)
+		}
+		disconnected, connected := blockchain.connectBlock(b)
+		if len(disconnected) != 0 || len(connected) != 0 {
+			t.Errorf("a branch which isn't longer should not change the active chain")
+		}
+		if !bytes.Equal(blockchain.BestBlockHash(), old3.CalHash()) {
+			t.Errorf("expected the tip to stay at block 3")
+		}
+	}
+	if h, _ := blockchain.BlockHeight(fork3.CalHash()); h != 3 {
+		t.Errorf("expected the side block at height 3 but got %d", h)
+	}
+	if c, _ := blockchain.Confirmations(fork3.CalHash()); c != -1 {
+		t.Errorf("side block should not be confirmed but got %d confirmations", c)
+	}
+	if c, _ := blockchain.Confirmations(old2.CalHash()); c != 2 {
+		t.Errorf("expected 2 confirmations but got %d", c)
+	}
+
+	// a longer branch replaces blocks 2 and 3
+	fork4 := forkBlock(blockchain, miner, fork3)
+	if err := blockchain.validateBlock(fork4); err != nil {
+		t.Fatal(err)
+	}
+	disconnected, connected := blockchain.connectBlock(fork4)
+	if len(disconnected) != 2 || !bytes.Equal(disconnected[0].CalHash(), old3.CalHash()) || !bytes.Equal(disconnected[1].CalHash(), old2.CalHash()) {
+		t.Errorf("expected blocks 3 and 2 to be disconnected")
+	}
+	if len(connected) != 3 || !bytes.Equal(connected[0].CalHash(), fork2.CalHash()) || !bytes.Equal(connected[2].CalHash(), fork4.CalHash()) {
+		t.Errorf("expected the fork blocks to be connected")
+	}
+	if h := blockchain.Height(); h != 4 {
+		t.Errorf("expected height 4 but got %d", h)
+	}
+	for height, b := range map[int]*Block{2: fork2, 3: fork3, 4: fork4} {
+		if active, _ := blockchain.GetBlockByHeight(height); !bytes.Equal(active.CalHash(), b.CalHash()) {
+			t.Errorf("expected the fork block at height %d", height)
+		}
+	}
+	if c, _ := blockchain.Confirmations(old2.CalHash()); c != -1 {
+		t.Errorf("disconnected block should not be confirmed but got %d confirmations", c)
+	}
+	if c, _ := blockchain.Confirmations(fork2.CalHash()); c != 3 {
+		t.Errorf("expected 3 confirmations but got %d", c)
+	}
+}
@@ -0,0 +1,179 @@
+package sc
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// UTXO an unspent transaction output ready to be spent
+type UTXO struct {
+	TxIn         TxIn
+	Value        Amount
+	ScriptPubKey string
+}
+
+// SelectionStats describe the inputs picked by a coin selection
+type SelectionStats struct {
+	Inputs int
+	Total  Amount
+	Change Amount
+}
+
+// CoinSelector pick the outputs to spend for paying the target amount
+type CoinSelector interface {
+	Select(utxos []UTXO, target Amount) ([]UTXO, error)
+}
+
+// LargestFirst spend the largest outputs first; it uses few inputs
+type LargestFirst struct{}
+
+// Select pick the largest outputs until the target is reached
+func (LargestFirst) Select(utxos []UTXO, target Amount) ([]UTXO, error) {
+	sorted := sortUTXOs(utxos, func(a, b UTXO) bool { return a.Value > b.Value })
+	return accumulate(sorted, target)
+}
+
+// SmallestFirst spend the smallest outputs first; it consolidates dust
+type SmallestFirst struct{}
+
+// Select pick the smallest outputs until the target is reached
+func (SmallestFirst) Select(utxos []UTXO, target Amount) ([]UTXO, error) {
+	sorted := sortUTXOs(utxos, func(a, b UTXO) bool { return a.Value < b.Value })
+	return accumulate(sorted, target)
+}
+
+// BranchAndBound search for outputs matching the target exactly so no change is needed.
+// When there is no exact match within MaxTries it falls back to Fallback, or LargestFirst if not set
+type BranchAndBound struct {
+	MaxTries int
+	Fallback CoinSelector
+}
+
+// Select pick outputs summing exactly to the target if possible
+func (bnb BranchAndBound) Select(utxos []UTXO, target Amount) ([]UTXO, error) {
+	maxTries := bnb.MaxTries
+	if maxTries <= 0 {
+		maxTries = 100000
+	}
+	sorted := sortUTXOs(utxos, func(a, b UTXO) bool { return a.Value > b.Value })
+	// remaining[i] is the sum of the outputs from i to the end
+	remaining := make([]Amount, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + sorted[i].Value
+	}
+	tries := 0
+	picked := make([]int, 0)
+	var search func(i int, sum Amount) bool
+	search = func(i int, sum Amount) bool {
+		tries++
+		if sum == target && len(picked) > 0 {
+			return true
+		}
+		if i == len(sorted) || sum > target || sum+remaining[i] < target || tries > maxTries {
+			return false
+		}
+		// include the output first, then explore without it
+		picked = append(picked, i)
+		if search(i+1, sum+sorted[i].Value) {
+			return true
+		}
+		picked = picked[:len(picked)-1]
+		return search(i+1, sum)
+	}
+	if search(0, 0) {
+		selected := make([]UTXO, 0, len(picked))
+		for _, i := range picked {
+			selected = append(selected, sorted[i])
+		}
+		return selected, nil
+	}
+	fallback := bnb.Fallback
+	if fallback == nil {
+		fallback = LargestFirst{}
+	}
+	return fallback.Select(utxos, target)
+}
+
+// RandomImprove pick random outputs until the target is reached, then keep adding random outputs
+// while it brings the total closer to twice the target without exceeding three times the target.
+// The change ends up about as large as the payment which keeps the outputs useful for later payments
+type RandomImprove struct {
+	Rand *rand.Rand
+}
+
+// Select pick random outputs and improve the selection
+func (ri RandomImprove) Select(utxos []UTXO, target Amount) ([]UTXO, error) {
+	shuffled := append([]UTXO{}, utxos...)
+	shuffle := rand.Shuffle
+	if ri.Rand != nil {
+		shuffle = ri.Rand.Shuffle
+	}
+	shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	selected, err := accumulate(shuffled, target)
+	if err != nil {
+		return nil, err
+	}
+	total := sumUTXOs(selected)
+	ideal, limit := 2*target, 3*target
+	for _, u := range shuffled[len(selected):] {
+		next := total + u.Value
+		if next > limit || distance(next, ideal) >= distance(total, ideal) {
+			continue
+		}
+		selected = append(selected, u)
+		total = next
+	}
+	return selected, nil
+}
+
+// newSelectionStats describe the selected outputs paying the target amount
+func newSelectionStats(selected []UTXO, target Amount) SelectionStats {
+	total := sumUTXOs(selected)
+	return SelectionStats{
+		Inputs: len(selected),
+		Total:  total,
+		Change: total - target,
+	}
+}
+
+// accumulate take the outputs in order until the target is reached
+func accumulate(utxos []UTXO, target Amount) ([]UTXO, error) {
+	selected := make([]UTXO, 0)
+	total := Amount(0)
+	for _, u := range utxos {
+		if total >= target && len(selected) > 0 {
+			break
+		}
+		selected = append(selected, u)
+		total += u.Value
+	}
+	if total < target || len(selected) == 0 {
+		return nil, errorNotEnoughMoney
+	}
+	return selected, nil
+}
+
+func sortUTXOs(utxos []UTXO, less func(a, b UTXO) bool) []UTXO {
+	sorted := append([]UTXO{}, utxos...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+func sumUTXOs(utxos []UTXO) Amount {
+	total := Amount(0)
+	for _, u := range utxos {
+		total += u.Value
+	}
+	return total
+}
+
+func distance(a, b Amount) Amount {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,94 @@
+package sc
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func utxosOf(values ...Amount) []UTXO {
+	utxos := make([]UTXO, 0, len(values))
+	for i, v := range values {
+		utxos = append(utxos, UTXO{TxIn: TxIn{Vout: i}, Value: v})
+	}
+	return utxos
+}
+
+func TestCoinSelectors(t *testing.T) {
+	utxos := utxosOf(1*Coin, 5*Coin, 2*Coin, 3*Coin)
+	tests := []struct {
+		name     string
+		selector CoinSelector
+		target   Amount
+		inputs   int
+		change   Amount
+	}{
+		{"largest first", LargestFirst{}, 6 * Coin, 2, 2 * Coin},
+		{"smallest first", SmallestFirst{}, 6 * Coin, 3, 0},
+		{"branch and bound exact", BranchAndBound{}, 9 * Coin, 3, 0},
+		{"branch and bound fallback", BranchAndBound{}, 11*Coin + 1, 0, 0},
+		{"branch and bound no match", BranchAndBound{Fallback: SmallestFirst{}}, 5*Coin + 1, 3, 1*Coin - 1},
+	}
+	for _, test := range tests {
+		selected, err := test.selector.Select(utxos, test.target)
+		if test.inputs == 0 {
+			if err != errorNotEnoughMoney {
+				t.Errorf("%s: expected not enough money but got %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		stats := newSelectionStats(selected, test.target)
+		if stats.Inputs != test.inputs || stats.Change != test.change {
+			t.Errorf("%s: expected %d inputs and %s change but got %d inputs and %s change",
+				test.name, test.inputs, test.change, stats.Inputs, stats.Change)
+		}
+	}
+}
+
+func TestRandomImprove(t *testing.T) {
+	utxos := utxosOf(1*Coin, 1*Coin, 1*Coin, 1*Coin, 1*Coin, 1*Coin, 1*Coin, 1*Coin)
+	selector := RandomImprove{Rand: rand.New(rand.NewSource(1))}
+	selected, err := selector.Select(utxos, 2*Coin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the change is improved up to the payment amount
+	assertEquals(t, "change", newSelectionStats(selected, 2*Coin).Change, 2*Coin)
+	if _, err := selector.Select(utxos, 9*Coin); err != errorNotEnoughMoney {
+		t.Errorf("expected not enough money but got %v", err)
+	}
+}
+
+func TestSendUsingCoinSelector(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	bob := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+	wallet := NewMemWallet(blockchain)
+	wallet.Add("miner", miner)
+	wallet.Add("alice", alice)
+	wallet.Add("bob", bob)
+	for _, v := range []Amount{1 * Coin, 2 * Coin, 3 * Coin} {
+		wallet.Send("miner", "alice", v)
+	}
+
+	stats := wallet.SendUsing("alice", "bob", 3*Coin, 0, BranchAndBound{})
+	if stats.Inputs != 1 || stats.Change != 0 {
+		t.Errorf("expected an exact match with one input but got %d inputs and %s change", stats.Inputs, stats.Change)
+	}
+	stats = wallet.SendUsing("alice", "bob", 1*Coin/2, 0, SmallestFirst{})
+	if stats.Inputs != 1 || stats.Change != 1*Coin/2 {
+		t.Errorf("expected the smallest input but got %d inputs and %s change", stats.Inputs, stats.Change)
+	}
+	// the unselected output is still there
+	_, _, utxos := blockchain.unspent(alice)
+	if len(utxos) != 2 {
+		t.Errorf("expected 2 unspent outputs but got %d", len(utxos))
+	}
+	assertEquals(t, "alice", wallet.Balance("alice"), 2*Coin+1*Coin/2)
+	assertEquals(t, "bob", wallet.Balance("bob"), 3*Coin+1*Coin/2)
+}
@@ -86,6 +86,7 @@ func (db *MemDatabase) Close() {}
 // BlockIterator represent block ite
 type BlockIterator struct {
 	current *Block
+	from    Hash
 	db      Database
 }
 
@@ -95,11 +96,21 @@ func NewBlockIterator(db Database) BlockIterator {
 	}
 }
 
+// NewBlockIteratorFrom return an iterator walking backward from the block with the given hash
+func NewBlockIteratorFrom(db Database, hash Hash) BlockIterator {
+	return BlockIterator{
+		db:   db,
+		from: hash,
+	}
+}
+
 func (it *BlockIterator) Next() *Block {
 	var b *Block
 	var v []byte
 	var err error
-	if it.current == nil {
+	if it.current == nil && len(it.from) != 0 {
+		v, err = it.db.Get(it.from)
+	} else if it.current == nil {
 		v, err = it.db.Get(lastBlockKey)
 	} else if len(it.current.PrevHash) == 0 { // genesis
 		return nil
@@ -0,0 +1,140 @@
+package sc
+
+import (
+	"sync"
+)
+
+// EventType kind of chain event
+type EventType int
+
+const (
+	// BlockConnected a block joined the active chain
+	BlockConnected EventType = iota
+	// BlockDisconnected a block left the active chain because another branch replaced it
+	BlockDisconnected
+	// TipChanged the last block of the active chain changed
+	TipChanged
+	// TransactionAccepted a transaction was included in a block joining the active chain
+	TransactionAccepted
+	// TransactionRejected a transaction was refused; Err tells why
+	TransactionRejected
+)
+
+var eventTypeNames = []string{"BlockConnected", "BlockDisconnected", "TipChanged", "TransactionAccepted", "TransactionRejected"}
+
+func (t EventType) String() string {
+	if t < 0 || int(t) >= len(eventTypeNames) {
+		return "Unknown"
+	}
+	return eventTypeNames[t]
+}
+
+// Event something that happened to the chain. Block and Height are set for block events and
+// for accepted transactions, Tx for transaction events
+type Event struct {
+	Type   EventType
+	Block  *Block
+	Height int
+	Tx     *Transaction
+	Err    error
+}
+
+// Subscription receive the chain events of the subscribed types on C. Events are dropped
+// instead of blocking the chain when the buffer of C is full
+type Subscription struct {
+	C       <-chan Event
+	ch      chan Event
+	types   map[EventType]bool
+	bus     *eventBus
+	dropped int
+}
+
+// Unsubscribe stop receiving events and close C
+func (s *Subscription) Unsubscribe() {
+	s.bus.remove(s)
+}
+
+// Dropped return the number of events dropped because the buffer was full
+func (s *Subscription) Dropped() int {
+	s.bus.lock.Lock()
+	defer s.bus.lock.Unlock()
+	return s.dropped
+}
+
+// eventBus deliver the events to the subscriptions
+type eventBus struct {
+	lock          sync.Mutex
+	subscriptions map[*Subscription]bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		subscriptions: make(map[*Subscription]bool),
+	}
+}
+
+func (bus *eventBus) remove(s *Subscription) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	if bus.subscriptions[s] {
+		delete(bus.subscriptions, s)
+		close(s.ch)
+	}
+}
+
+func (bus *eventBus) publish(e Event) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	for s := range bus.subscriptions {
+		if len(s.types) > 0 && !s.types[e.Type] {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// Subscribe return a subscription receiving the events of the given types, or all events if
+// no type is given. At most buffer events wait to be read; a negative buffer is taken as 0
+func (bc *Blockchain) Subscribe(buffer int, types ...EventType) *Subscription {
+	if buffer < 0 {
+		buffer = 0
+	}
+	ch := make(chan Event, buffer)
+	s := &Subscription{
+		C:     ch,
+		ch:    ch,
+		types: make(map[EventType]bool),
+		bus:   bc.events,
+	}
+	for _, t := range types {
+		s.types[t] = true
+	}
+	bc.events.lock.Lock()
+	defer bc.events.lock.Unlock()
+	bc.events.subscriptions[s] = true
+	return s
+}
+
+// publishBlocks publish the events of a block added to the chain; nothing when the block
+// didn't change the active chain
+func (bc *Blockchain) publishBlocks(disconnected []*Block, connected []*Block) {
+	if len(connected) == 0 {
+		return
+	}
+	for _, b := range disconnected {
+		bc.events.publish(Event{Type: BlockDisconnected, Block: b, Height: bc.heightOf(b.CalHash())})
+	}
+	for _, b := range connected {
+		height := bc.heightOf(b.CalHash())
+		bc.events.publish(Event{Type: BlockConnected, Block: b, Height: height})
+		for _, tx := range b.Transactions[1:] {
+			bc.events.publish(Event{Type: TransactionAccepted, Block: b, Height: height, Tx: tx})
+		}
+	}
+	tip := connected[len(connected)-1]
+	bc.events.publish(Event{Type: TipChanged, Block: tip, Height: bc.heightOf(tip.CalHash())})
+}
@@ -0,0 +1,90 @@
+package sc
+
+import (
+	"bytes"
+	"testing"
+)
+
+// drain return the events waiting on the subscription
+func drain(s *Subscription) []Event {
+	events := make([]Event, 0)
+	for {
+		select {
+		case e := <-s.C:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	all := blockchain.Subscribe(100)
+	txs := blockchain.Subscribe(100, TransactionAccepted, TransactionRejected)
+
+	blockchain.Mine(1)
+	blockchain.Send(miner, testParams.Address(alice), Coin)
+	events := drain(all)
+	types := []EventType{BlockConnected, TipChanged, BlockConnected, TransactionAccepted, TipChanged}
+	if len(events) != len(types) {
+		t.Fatalf("expected %d events but got %d", len(types), len(events))
+	}
+	for i, e := range events {
+		if e.Type != types[i] {
+			t.Errorf("event %d: expected %s but got %s", i, types[i], e.Type)
+		}
+	}
+	if events[3].Height != 2 || events[3].Tx == nil {
+		t.Errorf("unexpected accepted transaction event %+v", events[3])
+	}
+
+	// spending the same output twice is rejected
+	sent := events[3].Tx
+	if err := blockchain.SendTransaction(sent); err == nil {
+		t.Fatalf("double spend should be rejected")
+	}
+	drain(all)
+	events = drain(txs)
+	if len(events) != 2 || events[1].Type != TransactionRejected || events[1].Err != errorMissingOutput || !bytes.Equal(events[1].Tx.ID, sent.ID) {
+		t.Errorf("expected the double spend to be rejected but got %+v", events)
+	}
+
+	// a longer branch on top of block 1 disconnects block 2
+	mineFork(blockchain, miner, 1, 2)
+	events = drain(all)
+	if len(events) < 3 || events[0].Type != BlockDisconnected || events[0].Height != 2 || events[len(events)-1].Type != TipChanged {
+		t.Errorf("expected block 2 to be disconnected but got %+v", events)
+	}
+
+	all.Unsubscribe()
+	if _, ok := <-all.C; ok {
+		t.Errorf("channel should be closed after unsubscribing")
+	}
+	blockchain.Mine(1)
+}
+
+func TestEventsBoundedBuffer(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	s := blockchain.Subscribe(2, BlockConnected)
+	blockchain.Mine(5)
+	if len(drain(s)) != 2 || s.Dropped() != 3 {
+		t.Errorf("expected 2 events and 3 dropped but got %d dropped", s.Dropped())
+	}
+}
+
+func TestEventsNegativeBuffer(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	s := blockchain.Subscribe(-1, BlockConnected)
+	blockchain.Mine(2)
+	if len(drain(s)) != 0 || s.Dropped() != 2 {
+		t.Errorf("expected no buffered events and 2 dropped but got %d dropped", s.Dropped())
+	}
+}
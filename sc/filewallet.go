@@ -0,0 +1,405 @@
+package sc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scryptParams the cost of deriving the encryption key from the passphrase. It is stored in the
+// wallet file next to the salt
+type scryptParams struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// defaultScryptCost the cost of new wallets unless WithScryptCost is given
+var defaultScryptCost = scryptParams{N: 1 << 15, R: 8, P: 1}
+
+const saltLen = 16
+const keyLen = 32
+
+var errorWalletLocked = errors.New("error: wallet is locked")
+var errorWrongPassphrase = errors.New("error: wrong passphrase")
+var errorAccountNotFound = errors.New("error: account not found")
+var errorAccountExists = errors.New("error: account already exists")
+var errorInvalidWalletFile = errors.New("error: invalid wallet file")
+
+// walletFile is the content of the wallet file
+type walletFile struct {
+	Scrypt     scryptParams `json:"scrypt"`
+	Salt       []byte       `json:"salt"`
+	Nonce      []byte       `json:"nonce"`
+	Ciphertext []byte       `json:"ciphertext"`
+}
+
+// walletContent is the encrypted part of the wallet file
+type walletContent struct {
+	Keys     map[string]string  `json:"keys"`
+	Watched  map[string]Address `json:"watched"`
+	Contacts map[string]Address `json:"contacts"`
+}
+
+// FileWallet represent a wallet persisted on disk. The private keys, the watch-only accounts and
+// the address book are encrypted with a key derived from the passphrase using scrypt and AES-GCM.
+// HD wallets are not stored in it: keep their mnemonic, RestoreHDWallet rebuilds them from the chain
+type FileWallet struct {
+	path string
+	bc   *Blockchain
+	cost scryptParams
+	salt []byte
+	key  []byte
+	mem  *MemWallet
+}
+
+// FileWalletOption configure a new wallet file
+type FileWalletOption func(w *FileWallet)
+
+// WithScryptCost derive the encryption key of the wallet with the given scrypt cost. A lower cost
+// unlocks faster but makes guessing the passphrase cheaper. The cost is kept when the passphrase changes
+func WithScryptCost(n, r, p int) FileWalletOption {
+	return func(w *FileWallet) {
+		w.cost = scryptParams{N: n, R: r, P: p}
+	}
+}
+
+// CreateFileWallet create a new empty wallet at the given path protected by the given passphrase.
+// The returned wallet is unlocked
+func CreateFileWallet(path string, passphrase string, bc *Blockchain, opts ...FileWalletOption) (*FileWallet, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("error: wallet file %s already exists", path)
+	}
+	w := &FileWallet{
+		path: path,
+		bc:   bc,
+		cost: defaultScryptCost,
+		mem:  NewMemWallet(bc),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if err := w.setPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	return w, w.save()
+}
+
+// OpenFileWallet open the wallet at the given path. The returned wallet is locked
+func OpenFileWallet(path string, bc *Blockchain) (*FileWallet, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return &FileWallet{
+		path: path,
+		bc:   bc,
+	}, nil
+}
+
+// Unlock decrypt the accounts using the given passphrase
+func (w *FileWallet) Unlock(passphrase string) error {
+	f, err := w.read()
+	if err != nil {
+		return err
+	}
+	if f.Scrypt.N == 0 {
+		return errorInvalidWalletFile
+	}
+	key, err := deriveKey(passphrase, f.Salt, f.Scrypt)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return errorWrongPassphrase
+	}
+	var content walletContent
+	if err := json.Unmarshal(plain, &content); err != nil {
+		return err
+	}
+	mem := NewMemWallet(w.bc)
+	for name, k := range content.Keys {
+		acc, err := ImportAccount(k)
+		if err != nil {
+			return err
+		}
+		mem.Add(name, acc)
+	}
+	for name, address := range content.Watched {
+		if err := mem.Watch(name, address); err != nil {
+			return err
+		}
+	}
+	for name, address := range content.Contacts {
+		if err := mem.AddContact(name, address); err != nil {
+			return err
+		}
+	}
+	w.cost = f.Scrypt
+	w.salt = f.Salt
+	w.key = key
+	w.mem = mem
+	return nil
+}
+
+// Lock forget the decrypted accounts and the encryption key
+func (w *FileWallet) Lock() {
+	w.key = nil
+	w.mem = nil
+}
+
+// IsLocked return true if the wallet is locked
+func (w *FileWallet) IsLocked() bool {
+	return w.mem == nil
+}
+
+// ChangePassphrase re-encrypt the wallet with the new passphrase
+func (w *FileWallet) ChangePassphrase(oldPassphrase, newPassphrase string) error {
+	if err := w.Unlock(oldPassphrase); err != nil {
+		return err
+	}
+	if err := w.setPassphrase(newPassphrase); err != nil {
+		return err
+	}
+	return w.save()
+}
+
+// ImportKey add an account from its hex encoded private key
+func (w *FileWallet) ImportKey(name string, key string) error {
+	if w.IsLocked() {
+		return errorWalletLocked
+	}
+	if _, ok := w.mem.accounts[name]; ok {
+		return errorAccountExists
+	}
+	acc, err := ImportAccount(key)
+	if err != nil {
+		return err
+	}
+	w.mem.Add(name, acc)
+	return w.save()
+}
+
+// ExportKey return the hex encoded private key of the account by the given name
+func (w *FileWallet) ExportKey(name string) (string, error) {
+	if w.IsLocked() {
+		return "", errorWalletLocked
+	}
+	acc, ok := w.mem.accounts[name]
+	if !ok {
+		return "", errorAccountNotFound
+	}
+	return acc.Export(), nil
+}
+
+// Info print information of the account by the given name
+func (w *FileWallet) Info(accName string) {
+	if w.IsLocked() {
+		fmt.Println(errorWalletLocked)
+		return
+	}
+	w.mem.Info(accName)
+}
+
+// Send sending money from an account to another account
+func (w *FileWallet) Send(from, to string, amount Amount) {
+	if w.IsLocked() {
+		fmt.Println(errorWalletLocked)
+		return
+	}
+	w.mem.Send(from, to, amount)
+}
+
+// SendTo pay the amount to the address with the given fee and return the transaction
+func (w *FileWallet) SendTo(from string, to Address, amount, fee Amount) (*Transaction, error) {
+	if w.IsLocked() {
+		return nil, errorWalletLocked
+	}
+	return w.mem.SendTo(from, to, amount, fee)
+}
+
+// SendCSV pay every <address>,<amount> line of the reader in a single transaction
+func (w *FileWallet) SendCSV(from string, r io.Reader) (*Transaction, error) {
+	if w.IsLocked() {
+		return nil, errorWalletLocked
+	}
+	return w.mem.SendCSV(from, r)
+}
+
+// History return the transactions of the account matching the query
+func (w *FileWallet) History(accName string, q HistoryQuery) []AddressTx {
+	if w.IsLocked() {
+		fmt.Println(errorWalletLocked)
+		return nil
+	}
+	return w.mem.History(accName, q)
+}
+
+// Watch add a watch-only account tracking the given address and save it to the wallet file
+func (w *FileWallet) Watch(name string, address Address) error {
+	if w.IsLocked() {
+		return errorWalletLocked
+	}
+	if err := w.mem.Watch(name, address); err != nil {
+		return err
+	}
+	return w.save()
+}
+
+// AddContact add the address to the address book and save it to the wallet file
+func (w *FileWallet) AddContact(name string, address Address) error {
+	if w.IsLocked() {
+		return errorWalletLocked
+	}
+	if err := w.mem.AddContact(name, address); err != nil {
+		return err
+	}
+	return w.save()
+}
+
+// RemoveContact remove the contact from the address book and the wallet file
+func (w *FileWallet) RemoveContact(name string) error {
+	if w.IsLocked() {
+		return errorWalletLocked
+	}
+	w.mem.RemoveContact(name)
+	return w.save()
+}
+
+// Contacts return a copy of the address book
+func (w *FileWallet) Contacts() map[string]Address {
+	if w.IsLocked() {
+		fmt.Println(errorWalletLocked)
+		return nil
+	}
+	return w.mem.Contacts()
+}
+
+// Publish anchor the hash of the given document on the chain and return the hash
+func (w *FileWallet) Publish(accName string, doc []byte) Hash {
+	if w.IsLocked() {
+		fmt.Println(errorWalletLocked)
+		return nil
+	}
+	return w.mem.Publish(accName, doc)
+}
+
+// Print info of all available accounts
+func (w *FileWallet) Print() {
+	if w.IsLocked() {
+		fmt.Println(errorWalletLocked)
+		return
+	}
+	w.mem.Print()
+}
+
+// Add a new account and save it to the wallet file
+func (w *FileWallet) Add(name string, acc *Account) {
+	if w.IsLocked() {
+		fmt.Println(errorWalletLocked)
+		return
+	}
+	w.mem.Add(name, acc)
+	if err := w.save(); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// Balance return the available balance of the given account
+func (w *FileWallet) Balance(accName string) Amount {
+	if w.IsLocked() {
+		fmt.Println(errorWalletLocked)
+		return 0
+	}
+	return w.mem.Balance(accName)
+}
+
+// setPassphrase derive a new encryption key from the passphrase with a fresh salt
+func (w *FileWallet) setPassphrase(passphrase string) error {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return err
+	}
+	key, err := deriveKey(passphrase, salt, w.cost)
+	if err != nil {
+		return err
+	}
+	w.salt = salt
+	w.key = key
+	return nil
+}
+
+// save encrypt the accounts and write them to the wallet file
+func (w *FileWallet) save() error {
+	content := walletContent{
+		Keys:     make(map[string]string),
+		Watched:  w.mem.watched,
+		Contacts: w.mem.contacts,
+	}
+	for name, acc := range w.mem.accounts {
+		content.Keys[name] = acc.Export()
+	}
+	plain, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(w.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	data, err := json.Marshal(walletFile{
+		Scrypt:     w.cost,
+		Salt:       w.salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plain, nil),
+	})
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first so a crash never leaves a half written wallet
+	tmp := w.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.path)
+}
+
+func (w *FileWallet) read() (*walletFile, error) {
+	data, err := ioutil.ReadFile(w.path)
+	if err != nil {
+		return nil, err
+	}
+	f := &walletFile{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func deriveKey(passphrase string, salt []byte, cost scryptParams) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, cost.N, cost.R, cost.P, keyLen)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
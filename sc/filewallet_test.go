@@ -0,0 +1,99 @@
+package sc
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileWallet(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	path := filepath.Join(t.TempDir(), "wallet.json")
+
+	// keep the key derivation cheap for the tests
+	w, err := CreateFileWallet(path, "secret", blockchain, WithScryptCost(1<<10, 8, 1))
+	if err != nil {
+		t.Fatalf("failed to create wallet: %v", err)
+	}
+	w.Add("miner", miner)
+	if err := w.ImportKey("alice", NewAccount().Export()); err != nil {
+		t.Fatalf("failed to import key: %v", err)
+	}
+	bob := NewAccount()
+	if err := w.Watch("bob", testParams.Address(bob)); err != nil {
+		t.Fatalf("failed to watch address: %v", err)
+	}
+	carol := testParams.Address(NewAccount())
+	if err := w.AddContact("carol", carol); err != nil {
+		t.Fatalf("failed to add contact: %v", err)
+	}
+	blockchain.Mine(1)
+	w.Send("miner", "alice", 2*Coin)
+	if _, err := w.SendTo("miner", testParams.Address(bob), Coin, 0); err != nil {
+		t.Fatalf("failed to send to bob: %v", err)
+	}
+
+	// the accounts, the watch-only accounts and the contacts survive reopening the wallet
+	w, err = OpenFileWallet(path, blockchain)
+	if err != nil {
+		t.Fatalf("failed to open wallet: %v", err)
+	}
+	if _, err := w.ExportKey("miner"); err != errorWalletLocked {
+		t.Errorf("locked wallet should not export keys but got %v", err)
+	}
+	if err := w.Unlock("wrong"); err != errorWrongPassphrase {
+		t.Errorf("expected %v but got %v", errorWrongPassphrase, err)
+	}
+	if err := w.Unlock("secret"); err != nil {
+		t.Fatalf("failed to unlock wallet: %v", err)
+	}
+	assertEquals(t, "alice", 2*Coin, w.Balance("alice"))
+	assertEquals(t, "bob", Coin, w.Balance("bob"))
+	if address, ok := w.Contacts()["carol"]; !ok || address.String() != carol.String() {
+		t.Errorf("contact should be restored")
+	}
+	if history := w.History("bob", HistoryQuery{}); len(history) != 1 || history[0].Received != Coin {
+		t.Errorf("unexpected history of bob %+v", history)
+	}
+	if err := w.RemoveContact("carol"); err != nil {
+		t.Fatalf("failed to remove contact: %v", err)
+	}
+	key, err := w.ExportKey("miner")
+	if err != nil || key != miner.Export() {
+		t.Errorf("exported key does not match the miner key")
+	}
+
+	if err := w.ChangePassphrase("secret", "new secret"); err != nil {
+		t.Fatalf("failed to change passphrase: %v", err)
+	}
+	w.Lock()
+	if err := w.Unlock("secret"); err != errorWrongPassphrase {
+		t.Errorf("old passphrase should not unlock the wallet but got %v", err)
+	}
+	if err := w.Unlock("new secret"); err != nil {
+		t.Fatalf("failed to unlock wallet with the new passphrase: %v", err)
+	}
+	assertEquals(t, "miner", 12*Coin, w.Balance("miner"))
+	if len(w.Contacts()) != 0 {
+		t.Errorf("removed contact should stay removed")
+	}
+	w.Lock()
+	if _, err := w.SendTo("miner", testParams.Address(bob), Coin, 0); err != errorWalletLocked {
+		t.Errorf("locked wallet should not send but got %v", err)
+	}
+}
+
+func TestImportAccount(t *testing.T) {
+	acc := NewAccount()
+	imported, err := ImportAccount(acc.Export())
+	if err != nil {
+		t.Fatalf("failed to import account: %v", err)
+	}
+	if string(testParams.Address(imported)) != string(testParams.Address(acc)) {
+		t.Errorf("imported account should have the same address")
+	}
+	if _, err := ImportAccount("not a key"); err != errorInvalidKey {
+		t.Errorf("expected %v but got %v", errorInvalidKey, err)
+	}
+}
@@ -0,0 +1,33 @@
+package sc
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errorWrongGenesis = errors.New("error: genesis block doesn't match the network")
+
+// GenesisBlock return the genesis block of the network. It is built from the parameters only
+// so every node starts from the same block, whose hash is GenesisHash
+func (p *ChainParams) GenesisBlock() *Block {
+	coinbase := &Transaction{
+		Vin: []TxIn{{
+			Txid:      []byte{},
+			Vout:      -1,
+			ScriptSig: []byte(p.GenesisMessage),
+		}},
+		Vout: []TxOut{{
+			Value:        p.Subsidy(0),
+			ScriptPubKey: fmt.Sprintf(scriptPubKey, p.GenesisAddress),
+		}},
+	}
+	// unlike other transactions the id doesn't depend on the time it is created
+	coinbase.ID = coinbase.CalHash()
+	return &Block{
+		Timestamp:    p.GenesisTime,
+		PrevHash:     Hash{},
+		Difficulty:   p.Difficulty,
+		Nonce:        p.GenesisNonce,
+		Transactions: []*Transaction{coinbase},
+	}
+}
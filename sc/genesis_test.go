@@ -0,0 +1,65 @@
+package sc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenesisIsFixed(t *testing.T) {
+	for _, params := range []*ChainParams{MainNet(), TestNet(), RegTest()} {
+		b := params.GenesisBlock()
+		if !bytes.Equal(b.CalHash(), params.GenesisHash) {
+			t.Errorf("%s: genesis hash %s doesn't match %s", params.Name, b.CalHash(), params.GenesisHash)
+		}
+		if err := b.CheckSanity(params); err != nil {
+			t.Errorf("%s: invalid genesis: %v", params.Name, err)
+		}
+	}
+
+	// two nodes agree on the genesis
+	db1, _ := NewMemDatabase()
+	db2, _ := NewMemDatabase()
+	bc1 := NewBlockchain(testParams, NewAccount(), db1)
+	bc2 := NewBlockchain(testParams, NewAccount(), db2)
+	if !bytes.Equal(bc1.BestBlockHash(), bc2.BestBlockHash()) || !bytes.Equal(bc1.BestBlockHash(), RegTest().GenesisHash) {
+		t.Errorf("expected the regtest genesis on both chains")
+	}
+}
+
+func TestWrongGenesisRejected(t *testing.T) {
+	db, _ := NewMemDatabase()
+	bc := NewBlockchain(testParams, NewAccount(), db)
+	bc.Mine(1)
+
+	// the database of regtest can't be opened as testnet
+	if _, err := OpenBlockchain(TestNet(), NewAccount(), db); err != errorWrongGenesis {
+		t.Errorf("expected %v but got %v", errorWrongGenesis, err)
+	}
+	if _, err := OpenBlockchain(testParams, NewAccount(), db); err != nil {
+		t.Errorf("expected the database to open but got %v", err)
+	}
+
+	// parameters whose genesis doesn't hash to the compiled-in hash are refused
+	params := *testParams
+	params.GenesisMessage = "another genesis"
+	empty, _ := NewMemDatabase()
+	if _, err := OpenBlockchain(&params, NewAccount(), empty); err != errorWrongGenesis {
+		t.Errorf("expected %v but got %v", errorWrongGenesis, err)
+	}
+	// so are parameters whose rules the genesis breaks
+	params = *testParams
+	params.MaxBlockSize = 10
+	if _, err := OpenBlockchain(&params, NewAccount(), empty); err != errorBlockTooLarge {
+		t.Errorf("expected %v but got %v", errorBlockTooLarge, err)
+	}
+	if _, err := OpenBlockchain(&params, NewAccount(), db); err != errorBlockTooLarge {
+		t.Errorf("expected %v but got %v", errorBlockTooLarge, err)
+	}
+
+	// another genesis can't be added later
+	other := testParams.newBlock([]*Transaction{NewCoinbase(bc.ScriptPubKey(testParams.Address(NewAccount())), Coin)}, Hash{})
+	other.Nonce = testParams.PoWer.Work(other)
+	if err := bc.validateBlock(other); err != errorWrongGenesis {
+		t.Errorf("expected %v but got %v", errorWrongGenesis, err)
+	}
+}
@@ -0,0 +1,238 @@
+package sc
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// HardenedKeyStart index of the first hardened child key
+const HardenedKeyStart = uint32(0x80000000)
+
+const extendedKeyLen = 82
+
+var masterKeySecret = []byte("simcoin seed")
+
+// version bytes of the serialized extended keys; they make them start with "sprv" and "spub"
+var xprvVersion = []byte{0x04, 0x20, 0xb9, 0x00}
+var xpubVersion = []byte{0x04, 0x20, 0xbd, 0x3a}
+
+var errorInvalidSeed = errors.New("error: seed must be between 16 and 64 bytes")
+var errorInvalidChild = errors.New("error: derived key is invalid, use the next index")
+var errorHardenedFromPublic = errors.New("error: cannot derive a hardened key from a public key")
+var errorNotPrivate = errors.New("error: extended key is not private")
+var errorInvalidPath = errors.New("error: invalid derivation path")
+var errorInvalidExtendedKey = errors.New("error: invalid extended key")
+
+// ExtendedKey a BIP32-style extended key: a private or public key plus a chain code
+// which allows to derive a tree of child keys
+type ExtendedKey struct {
+	key       []byte // 32 bytes private key or 33 bytes compressed public key
+	chainCode []byte
+	depth     uint8
+	parentFP  []byte
+	childNum  uint32
+	isPrivate bool
+}
+
+// NewMasterKey return the root extended private key of the given seed
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errorInvalidSeed
+	}
+	mac := hmac.New(sha512.New, masterKeySecret)
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	k := new(big.Int).SetBytes(I[:32])
+	if k.Sign() == 0 || k.Cmp(elliptic.P256().Params().N) >= 0 {
+		return nil, errorInvalidSeed
+	}
+	return &ExtendedKey{
+		key:       I[:32],
+		chainCode: I[32:],
+		parentFP:  []byte{0, 0, 0, 0},
+		isPrivate: true,
+	}, nil
+}
+
+// IsPrivate return true if the extended key holds a private key
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.isPrivate
+}
+
+// Child derive the child key at the given index. Indexes from HardenedKeyStart derive hardened keys
+// which need the private key
+func (k *ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
+	curve := elliptic.P256()
+	data := make([]byte, 0, 37)
+	if i >= HardenedKeyStart {
+		if !k.isPrivate {
+			return nil, errorHardenedFromPublic
+		}
+		data = append(data, 0x00)
+		data = append(data, k.key...)
+	} else {
+		data = append(data, k.pubKeyBytes()...)
+	}
+	data = binary.BigEndian.AppendUint32(data, i)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	I := mac.Sum(nil)
+	il := new(big.Int).SetBytes(I[:32])
+	if il.Cmp(curve.Params().N) >= 0 {
+		return nil, errorInvalidChild
+	}
+
+	var childKey []byte
+	if k.isPrivate {
+		child := new(big.Int).Add(il, new(big.Int).SetBytes(k.key))
+		child.Mod(child, curve.Params().N)
+		if child.Sign() == 0 {
+			return nil, errorInvalidChild
+		}
+		childKey = child.FillBytes(make([]byte, 32))
+	} else {
+		x, y := elliptic.UnmarshalCompressed(curve, k.key)
+		ilx, ily := curve.ScalarBaseMult(I[:32])
+		cx, cy := curve.Add(ilx, ily, x, y)
+		if cx.Sign() == 0 && cy.Sign() == 0 {
+			return nil, errorInvalidChild
+		}
+		childKey = elliptic.MarshalCompressed(curve, cx, cy)
+	}
+	return &ExtendedKey{
+		key:       childKey,
+		chainCode: I[32:],
+		depth:     k.depth + 1,
+		parentFP:  hash160(k.pubKeyBytes())[:4],
+		childNum:  i,
+		isPrivate: k.isPrivate,
+	}, nil
+}
+
+// Derive follow the given path like "m/0'/1/2" from this key. The leading "m" is optional;
+// an apostrophe marks a hardened index
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	key := k
+	for idx, p := range strings.Split(path, "/") {
+		if idx == 0 && (p == "m" || p == "M") {
+			continue
+		}
+		i := uint32(0)
+		if strings.HasSuffix(p, "'") {
+			i = HardenedKeyStart
+			p = strings.TrimSuffix(p, "'")
+		}
+		v, err := strconv.ParseUint(p, 10, 31)
+		if err != nil {
+			return nil, errorInvalidPath
+		}
+		if key, err = key.Child(i + uint32(v)); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Neuter return the extended public key of this key
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.isPrivate {
+		return k
+	}
+	return &ExtendedKey{
+		key:       k.pubKeyBytes(),
+		chainCode: k.chainCode,
+		depth:     k.depth,
+		parentFP:  k.parentFP,
+		childNum:  k.childNum,
+	}
+}
+
+// Account return the account holding the private key of this extended key
+func (k *ExtendedKey) Account() (*Account, error) {
+	if !k.isPrivate {
+		return nil, errorNotPrivate
+	}
+	return ImportAccount(hex.EncodeToString(k.key))
+}
+
+// Address return the address of this extended key on the given network; it works for both private and public keys
+func (k *ExtendedKey) Address(params *ChainParams) Address {
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), k.pubKeyBytes())
+	return pubKeyToAddress(toPubKey(ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}), params.AddressVersion)
+}
+
+// String serialize the extended key using base58 with checksum
+func (k *ExtendedKey) String() string {
+	payload := make([]byte, 0, extendedKeyLen)
+	if k.isPrivate {
+		payload = append(payload, xprvVersion...)
+	} else {
+		payload = append(payload, xpubVersion...)
+	}
+	payload = append(payload, k.depth)
+	payload = append(payload, k.parentFP...)
+	payload = binary.BigEndian.AppendUint32(payload, k.childNum)
+	payload = append(payload, k.chainCode...)
+	if k.isPrivate {
+		payload = append(payload, 0x00)
+	}
+	payload = append(payload, k.key...)
+	payload = append(payload, checksum(payload)...)
+	return EncodeBase58(payload)
+}
+
+// ParseExtendedKey parse an extended key serialized by ExtendedKey.String
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	payload := DecodeBase58(s)
+	if len(payload) != extendedKeyLen {
+		return nil, errorInvalidExtendedKey
+	}
+	data, sum := payload[:len(payload)-addressChecksumLen], payload[len(payload)-addressChecksumLen:]
+	if !bytes.Equal(checksum(data), sum) {
+		return nil, errorInvalidExtendedKey
+	}
+	k := &ExtendedKey{
+		depth:     data[4],
+		parentFP:  data[5:9],
+		childNum:  binary.BigEndian.Uint32(data[9:13]),
+		chainCode: data[13:45],
+	}
+	curve := elliptic.P256()
+	switch {
+	case bytes.Equal(data[:4], xprvVersion) && data[45] == 0x00:
+		k.isPrivate = true
+		k.key = data[46:]
+		d := new(big.Int).SetBytes(k.key)
+		if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+			return nil, errorInvalidExtendedKey
+		}
+	case bytes.Equal(data[:4], xpubVersion):
+		k.key = data[45:]
+		if x, _ := elliptic.UnmarshalCompressed(curve, k.key); x == nil {
+			return nil, errorInvalidExtendedKey
+		}
+	default:
+		return nil, errorInvalidExtendedKey
+	}
+	return k, nil
+}
+
+// pubKeyBytes return the compressed public key of this extended key
+func (k *ExtendedKey) pubKeyBytes() []byte {
+	if !k.isPrivate {
+		return k.key
+	}
+	curve := elliptic.P256()
+	x, y := curve.ScalarBaseMult(k.key)
+	return elliptic.MarshalCompressed(curve, x, y)
+}
@@ -0,0 +1,66 @@
+package sc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var testSeed = []byte("0123456789abcdef0123456789abcdef")
+
+func TestExtendedKeyDerivation(t *testing.T) {
+	master, err := NewMasterKey(testSeed)
+	if err != nil {
+		t.Fatalf("failed to create master key: %v", err)
+	}
+	priv, err := master.Derive("m/0'/1/2")
+	if err != nil {
+		t.Fatalf("failed to derive key: %v", err)
+	}
+	// the same address can be derived from the extended public key of the account
+	account, _ := master.Derive("m/0'")
+	pub, err := account.Neuter().Derive("1/2")
+	if err != nil {
+		t.Fatalf("failed to derive public key: %v", err)
+	}
+	if pub.IsPrivate() || !bytes.Equal(priv.Address(testParams), pub.Address(testParams)) {
+		t.Errorf("public derivation should give the same address as private derivation")
+	}
+	acc, err := priv.Account()
+	if err != nil || !bytes.Equal(testParams.Address(acc), priv.Address(testParams)) {
+		t.Errorf("account should have the address of the extended key")
+	}
+	if _, err := pub.Account(); err != errorNotPrivate {
+		t.Errorf("expected %v but got %v", errorNotPrivate, err)
+	}
+	if _, err := account.Neuter().Derive("0'"); err != errorHardenedFromPublic {
+		t.Errorf("expected %v but got %v", errorHardenedFromPublic, err)
+	}
+	if _, err := master.Derive("m/x"); err != errorInvalidPath {
+		t.Errorf("expected %v but got %v", errorInvalidPath, err)
+	}
+	if _, err := NewMasterKey([]byte("short")); err != errorInvalidSeed {
+		t.Errorf("expected %v but got %v", errorInvalidSeed, err)
+	}
+}
+
+func TestExtendedKeySerialization(t *testing.T) {
+	master, _ := NewMasterKey(testSeed)
+	key, _ := master.Derive("m/0'/1")
+	for _, k := range []*ExtendedKey{key, key.Neuter()} {
+		parsed, err := ParseExtendedKey(k.String())
+		if err != nil {
+			t.Fatalf("failed to parse extended key: %v", err)
+		}
+		if parsed.String() != k.String() || !bytes.Equal(parsed.Address(testParams), k.Address(testParams)) {
+			t.Errorf("parsed key should be the same as the original key")
+		}
+	}
+	if !strings.HasPrefix(key.String(), "sprv") || !strings.HasPrefix(key.Neuter().String(), "spub") {
+		t.Errorf("expected simcoin extended keys but got %s and %s", key.String(), key.Neuter().String())
+	}
+	s := key.String()
+	if _, err := ParseExtendedKey(s[:len(s)-1] + "1"); err != errorInvalidExtendedKey {
+		t.Errorf("expected %v but got %v", errorInvalidExtendedKey, err)
+	}
+}
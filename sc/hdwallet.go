@@ -0,0 +1,320 @@
+package sc
+
+import (
+	"fmt"
+)
+
+// chains of an HD account: receive addresses are derived from the external chain
+// and change addresses from the internal chain
+const externalChain = uint32(0)
+const internalChain = uint32(1)
+
+// gapLimit number of consecutive unused addresses after which restoring stops looking for more
+var gapLimit = 20
+
+// hdAccount an account of the HD wallet; its keys are derived from m/<index>'/<chain>/<n>
+type hdAccount struct {
+	index uint32
+	key   *ExtendedKey
+	keys  []*Account
+	next  [2]uint32
+}
+
+// HDWallet represent a hierarchical deterministic wallet. All keys are derived from a single seed
+// and a fresh address is used for every payment
+type HDWallet struct {
+	master   *ExtendedKey
+	bc       *Blockchain
+	accounts map[string]*hdAccount
+}
+
+// NewHDWallet return a new HD wallet deriving its keys from the given seed
+func NewHDWallet(seed []byte, bc *Blockchain) (*HDWallet, error) {
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &HDWallet{
+		master:   master,
+		bc:       bc,
+		accounts: make(map[string]*hdAccount),
+	}, nil
+}
+
+// CreateHDWallet return a new HD wallet with a fresh mnemonic. The mnemonic and the passphrase
+// are everything needed to restore the wallet
+func CreateHDWallet(passphrase string, bc *Blockchain) (*HDWallet, string, error) {
+	entropy, err := NewEntropy(128)
+	if err != nil {
+		return nil, "", err
+	}
+	mnemonic, err := NewMnemonic(entropy)
+	if err != nil {
+		return nil, "", err
+	}
+	w, err := NewHDWalletFromMnemonic(mnemonic, passphrase, bc)
+	return w, mnemonic, err
+}
+
+// NewHDWalletFromMnemonic return a new HD wallet deriving its keys from the given mnemonic and passphrase
+func NewHDWalletFromMnemonic(mnemonic string, passphrase string, bc *Blockchain) (*HDWallet, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	return NewHDWallet(MnemonicToSeed(mnemonic, passphrase), bc)
+}
+
+// RestoreHDWallet rebuild a wallet from its mnemonic by rescanning the chain for used addresses.
+// Accounts are discovered in order until one without any activity; they get the given names,
+// or "account<n>" when no name is given
+func RestoreHDWallet(mnemonic string, passphrase string, bc *Blockchain, names ...string) (*HDWallet, error) {
+	w, err := NewHDWalletFromMnemonic(mnemonic, passphrase, bc)
+	if err != nil {
+		return nil, err
+	}
+	bc.lock.RLock()
+	used := bc.usedScripts()
+	bc.lock.RUnlock()
+	for index := 0; ; index++ {
+		name := fmt.Sprintf("account%d", index)
+		if index < len(names) {
+			name = names[index]
+		}
+		if err := w.CreateAccount(name); err != nil {
+			return nil, err
+		}
+		acc := w.accounts[name]
+		found := false
+		for _, chain := range []uint32{externalChain, internalChain} {
+			ok, err := w.discover(acc, chain, used)
+			if err != nil {
+				return nil, err
+			}
+			found = found || ok
+		}
+		// keep the named accounts even if they were never used
+		if !found && index >= len(names) {
+			delete(w.accounts, name)
+			return w, nil
+		}
+	}
+}
+
+// discover derive the keys of the given chain of the account until gapLimit consecutive keys are unused.
+// It keeps the keys up to the last used one and return true if any key was used
+func (w *HDWallet) discover(acc *hdAccount, chain uint32, used map[string]bool) (bool, error) {
+	chainKey, err := acc.key.Child(chain)
+	if err != nil {
+		return false, err
+	}
+	found := false
+	pending := make([]*Account, 0)
+	for i, gap := uint32(0), 0; gap < gapLimit; i++ {
+		child, err := chainKey.Child(i)
+		if err == errorInvalidChild {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		key, err := child.Account()
+		if err != nil {
+			return false, err
+		}
+		pending = append(pending, key)
+		if !used[w.bc.ScriptPubKey(w.bc.params.Address(key))] {
+			gap++
+			continue
+		}
+		acc.keys = append(acc.keys, pending...)
+		acc.next[chain] = i + 1
+		pending = pending[:0]
+		gap = 0
+		found = true
+	}
+	return found, nil
+}
+
+// CreateAccount create a new account; accounts are numbered in the order they are created
+func (w *HDWallet) CreateAccount(name string) error {
+	if _, ok := w.accounts[name]; ok {
+		return errorAccountExists
+	}
+	index := uint32(len(w.accounts))
+	key, err := w.master.Child(HardenedKeyStart + index)
+	if err != nil {
+		return err
+	}
+	w.accounts[name] = &hdAccount{
+		index: index,
+		key:   key,
+		keys:  make([]*Account, 0),
+	}
+	return nil
+}
+
+// NewAddress return a fresh receive address of the account by the given name
+func (w *HDWallet) NewAddress(accName string) (Address, error) {
+	acc, ok := w.accounts[accName]
+	if !ok {
+		return nil, errorAccountNotFound
+	}
+	key, err := w.deriveNext(acc, externalChain)
+	if err != nil {
+		return nil, err
+	}
+	return w.bc.params.Address(key), nil
+}
+
+// AccountXPub return the extended public key of the account by the given name.
+// It can derive the receive addresses of the account without knowing the private keys
+func (w *HDWallet) AccountXPub(accName string) (string, error) {
+	acc, ok := w.accounts[accName]
+	if !ok {
+		return "", errorAccountNotFound
+	}
+	return acc.key.Neuter().String(), nil
+}
+
+// Info print information of the account by the given name
+func (w *HDWallet) Info(accName string) {
+	acc, ok := w.accounts[accName]
+	if !ok {
+		fmt.Printf("error: <%s> account not found\n", accName)
+		return
+	}
+	balance, immature := w.balance(acc)
+	fmt.Printf(`
+	----------------------------------
+		Name: %s
+		Path: m/%d'
+		Addresses: %d
+		Balance: %s
+		Immature: %s
+	----------------------------------
+		`, accName, acc.index, len(acc.keys), balance, immature)
+}
+
+// Send sending money from an account to a fresh address of another account, or to the given address
+func (w *HDWallet) Send(from, to string, amount Amount) error {
+	if _, ok := w.accounts[from]; !ok {
+		return errorAccountNotFound
+	}
+	if amount <= 0 || !amount.IsValid() {
+		return errorAmountOutOfRange
+	}
+	if amount > w.Balance(from) {
+		return errorNotEnoughMoney
+	}
+	address := Address(to)
+	if _, ok := w.accounts[to]; ok {
+		var err error
+		if address, err = w.NewAddress(to); err != nil {
+			return err
+		}
+	} else if !w.bc.params.ValidateAddress(to) {
+		return errorInvalidAddress
+	}
+	vouts := []TxOut{
+		TxOut{
+			Value:        amount,
+			ScriptPubKey: w.bc.ScriptPubKey(address),
+		},
+	}
+	return w.spend(w.accounts[from], amount, vouts)
+}
+
+// Publish anchor the hash of the given document on the chain and return the hash
+func (w *HDWallet) Publish(accName string, doc []byte) Hash {
+	if _, ok := w.accounts[accName]; !ok {
+		fmt.Printf("error: <%s> account not found\n", accName)
+		return nil
+	}
+	h := hash256(doc)
+	vouts := []TxOut{
+		TxOut{
+			Value:        0,
+			ScriptPubKey: w.bc.ScriptData(h),
+		},
+	}
+	if err := w.spend(w.accounts[accName], 0, vouts); err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	return h
+}
+
+// Print info of all available accounts
+func (w *HDWallet) Print() {
+	for accName := range w.accounts {
+		w.Info(accName)
+	}
+}
+
+// Add import a key into the account by the given name, creating the account if needed.
+// Imported keys are not derived from the seed so they are not covered by its backup
+func (w *HDWallet) Add(name string, acc *Account) {
+	if _, ok := w.accounts[name]; !ok {
+		if err := w.CreateAccount(name); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	w.accounts[name].keys = append(w.accounts[name].keys, acc)
+}
+
+// Balance return the available balance of the given account
+func (w *HDWallet) Balance(accName string) Amount {
+	acc, ok := w.accounts[accName]
+	if !ok {
+		return 0
+	}
+	balance, _ := w.balance(acc)
+	return balance
+}
+
+func (w *HDWallet) balance(acc *hdAccount) (available Amount, immature Amount) {
+	for _, key := range acc.keys {
+		a, i := w.bc.Balance(key)
+		available += a
+		immature += i
+	}
+	return
+}
+
+// spend pay the outputs with the money of all keys of the account. The change goes to a fresh change
+// address, derived only once the inputs are picked
+func (w *HDWallet) spend(acc *hdAccount, amount Amount, vouts []TxOut) error {
+	change := func() (*Account, error) {
+		return w.deriveNext(acc, internalChain)
+	}
+	tx, _, err := w.bc.newTransactionFrom(acc.keys, change, amount, 0, vouts, nil)
+	if err != nil {
+		return err
+	}
+	return w.bc.MineNewBlock([]*Transaction{tx})
+}
+
+// deriveNext derive the next key of the given chain of the account
+func (w *HDWallet) deriveNext(acc *hdAccount, chain uint32) (*Account, error) {
+	chainKey, err := acc.key.Child(chain)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		child, err := chainKey.Child(acc.next[chain])
+		acc.next[chain]++
+		if err == errorInvalidChild {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		key, err := child.Account()
+		if err != nil {
+			return nil, err
+		}
+		acc.keys = append(acc.keys, key)
+		return key, nil
+	}
+}
@@ -0,0 +1,193 @@
+package sc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHDWallet(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+
+	w, _ := NewHDWallet(testSeed, blockchain)
+	w.Add("miner", miner)
+	w.CreateAccount("alice")
+	blockchain.Mine(1)
+
+	w.Send("miner", "alice", 2*Coin)
+	w.Send("miner", "alice", 1*Coin)
+	assertEquals(t, "alice", 3*Coin, w.Balance("alice"))
+	assertEquals(t, "miner", 12*Coin, w.Balance("miner"))
+	if len(w.accounts["alice"].keys) != 2 {
+		t.Errorf("each payment should use a fresh address")
+	}
+
+	// the same seed derives the same addresses
+	restored, _ := NewHDWallet(testSeed, blockchain)
+	restored.CreateAccount("miner")
+	restored.CreateAccount("alice")
+	for _, key := range w.accounts["alice"].keys {
+		address, _ := restored.NewAddress("alice")
+		if !bytes.Equal(address, testParams.Address(key)) {
+			t.Errorf("restored wallet should derive the same addresses")
+		}
+	}
+	assertEquals(t, "alice", 3*Coin, restored.Balance("alice"))
+
+	xpub, _ := w.AccountXPub("alice")
+	key, _ := ParseExtendedKey(xpub)
+	first, _ := key.Derive("0/0")
+	if !bytes.Equal(first.Address(testParams), testParams.Address(w.accounts["alice"].keys[0])) {
+		t.Errorf("account xpub should derive the receive addresses")
+	}
+}
+
+func TestRestoreHDWallet(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+
+	w, mnemonic, err := CreateHDWallet("secret", blockchain)
+	if err != nil {
+		t.Fatalf("failed to create wallet: %v", err)
+	}
+	w.Add("miner", miner)
+	w.CreateAccount("alice")
+	w.CreateAccount("bob")
+	w.Send("miner", "alice", 2*Coin)
+	w.Send("miner", "alice", 1*Coin)
+	w.Send("alice", "bob", 1*Coin)
+
+	restored, err := RestoreHDWallet(mnemonic, "secret", blockchain, "miner", "alice")
+	if err != nil {
+		t.Fatalf("failed to restore wallet: %v", err)
+	}
+	// the miner key was imported so only the change sent to derived addresses is restored
+	assertEquals(t, "miner", 7*Coin, restored.Balance("miner"))
+	assertEquals(t, "alice", 2*Coin, restored.Balance("alice"))
+	assertEquals(t, "bob", 1*Coin, restored.Balance("account2"))
+	if len(restored.accounts) != 3 {
+		t.Errorf("expected 3 accounts but got %d", len(restored.accounts))
+	}
+	// new addresses continue after the used ones
+	address, _ := restored.NewAddress("alice")
+	expected, _ := w.NewAddress("alice")
+	if string(address) != string(expected) {
+		t.Errorf("restored wallet should continue with the next unused address")
+	}
+
+	other, _ := RestoreHDWallet(mnemonic, "wrong", blockchain, "miner", "alice")
+	assertEquals(t, "alice", 0, other.Balance("alice"))
+}
+
+func TestHDWalletSendErrors(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	w, _ := NewHDWallet(testSeed, blockchain)
+	w.Add("miner", miner)
+	w.CreateAccount("alice")
+	blockchain.Mine(1)
+
+	balance := w.Balance("miner")
+	tests := []struct {
+		from   string
+		to     string
+		amount Amount
+		err    error
+	}{
+		{"nobody", "alice", Coin, errorAccountNotFound},
+		{"miner", "alice", 0, errorAmountOutOfRange},
+		{"miner", "alice", -Coin, errorAmountOutOfRange},
+		{"miner", "alice", MaxAmount + 1, errorAmountOutOfRange},
+		{"miner", "alice", balance + 1, errorNotEnoughMoney},
+		{"alice", "miner", Coin, errorNotEnoughMoney},
+		{"miner", "not an address", Coin, errorInvalidAddress},
+	}
+	for _, test := range tests {
+		if err := w.Send(test.from, test.to, test.amount); err != test.err {
+			t.Errorf("sending %s from %s to %s: expected %v but got %v", test.amount, test.from, test.to, test.err, err)
+		}
+	}
+	// failed payments derive no address
+	for name, acc := range w.accounts {
+		if acc.next != [2]uint32{} {
+			t.Errorf("%s should not have derived any address but got %v", name, acc.next)
+		}
+	}
+	assertEquals(t, "miner", balance, w.Balance("miner"))
+}
+
+func TestHDWalletChange(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	w, _ := NewHDWallet(testSeed, blockchain)
+	w.Add("miner", miner)
+	w.CreateAccount("alice")
+	w.CreateAccount("bob")
+	blockchain.Mine(1)
+
+	if err := w.Send("miner", "alice", 3*Coin); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if err := w.Send("alice", "bob", Coin); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	alice := w.accounts["alice"]
+	if alice.next[internalChain] != 1 {
+		t.Fatalf("expected 1 change address but got %d", alice.next[internalChain])
+	}
+	// the change goes to the first key of the internal chain
+	change, _ := alice.key.Derive("1/0")
+	acc, _ := change.Account()
+	available, _ := blockchain.Balance(acc)
+	assertEquals(t, "change", 2*Coin, available)
+
+	// spending everything leaves no change, so no change address is derived
+	if err := w.Send("alice", "bob", 2*Coin); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if alice.next[internalChain] != 1 {
+		t.Errorf("payment without change should not derive a change address")
+	}
+	assertEquals(t, "alice", 0, w.Balance("alice"))
+	assertEquals(t, "bob", 3*Coin, w.Balance("bob"))
+}
+
+func TestRestoreHDWalletGap(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+
+	w, mnemonic, _ := CreateHDWallet("secret", blockchain)
+	w.Add("miner", miner)
+	w.CreateAccount("alice")
+	// pay to the 6th address, then to an address more than gapLimit unused addresses further
+	var near, far Address
+	for i := 0; i < 6+gapLimit+1; i++ {
+		address, _ := w.NewAddress("alice")
+		if i == 5 {
+			near = address
+		}
+		far = address
+	}
+	if err := w.Send("miner", string(near), 2*Coin); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if err := w.Send("miner", string(far), Coin); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	restored, err := RestoreHDWallet(mnemonic, "secret", blockchain, "miner", "alice")
+	if err != nil {
+		t.Fatalf("failed to restore wallet: %v", err)
+	}
+	assertEquals(t, "alice", 2*Coin, restored.Balance("alice"))
+	if next := restored.accounts["alice"].next[externalChain]; next != 6 {
+		t.Errorf("expected the restored receive chain to continue at 6 but got %d", next)
+	}
+}
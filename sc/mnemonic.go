@@ -0,0 +1,100 @@
+package sc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const seedIterations = 2048
+const seedLen = 64
+
+var errorInvalidEntropy = errors.New("error: entropy must be 128 to 256 bits and a multiple of 32 bits")
+var errorInvalidMnemonic = errors.New("error: invalid mnemonic")
+var errorMnemonicChecksum = errors.New("error: mnemonic checksum does not match")
+
+// NewEntropy return random entropy of the given number of bits for generating a mnemonic
+func NewEntropy(bits int) ([]byte, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return nil, errorInvalidEntropy
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := io.ReadFull(rand.Reader, entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// NewMnemonic encode the entropy and its checksum into a BIP39-style list of words
+func NewMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", errorInvalidEntropy
+	}
+	// append the first bits/32 bits of the sha256 of the entropy as checksum
+	csBits := uint(bits / 32)
+	v := new(big.Int).SetBytes(entropy)
+	v.Lsh(v, csBits)
+	v.Or(v, big.NewInt(int64(sha256.Sum256(entropy)[0]>>(8-csBits))))
+
+	// every 11 bits select a word
+	words := make([]string, (bits+int(csBits))/11)
+	mask := big.NewInt(2047)
+	for i := len(words) - 1; i >= 0; i-- {
+		idx := new(big.Int).And(v, mask)
+		words[i] = englishWordList[idx.Int64()]
+		v.Rsh(v, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decode the mnemonic and verify its checksum
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, errorInvalidMnemonic
+	}
+	v := new(big.Int)
+	for _, w := range words {
+		idx, ok := wordIndex[w]
+		if !ok {
+			return nil, errorInvalidMnemonic
+		}
+		v.Lsh(v, 11)
+		v.Or(v, big.NewInt(int64(idx)))
+	}
+	csBits := uint(len(words) * 11 / 33)
+	cs := new(big.Int).And(v, big.NewInt(int64(1<<csBits-1)))
+	v.Rsh(v, csBits)
+	entropy := v.FillBytes(make([]byte, (len(words)*11-int(csBits))/8))
+	if cs.Int64() != int64(sha256.Sum256(entropy)[0]>>(8-csBits)) {
+		return nil, errorMnemonicChecksum
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic check if the mnemonic is made of known words and its checksum is valid
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicToSeed derive the wallet seed from the mnemonic and an optional passphrase
+func MnemonicToSeed(mnemonic string, passphrase string) []byte {
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), seedIterations, seedLen, sha512.New)
+}
+
+var wordIndex = func() map[string]int {
+	idx := make(map[string]int, len(englishWordList))
+	for i, w := range englishWordList {
+		idx[w] = i
+	}
+	return idx
+}()
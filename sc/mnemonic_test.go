@@ -0,0 +1,42 @@
+package sc
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestMnemonic(t *testing.T) {
+	// test vector from BIP39
+	entropy := make([]byte, 16)
+	mnemonic, err := NewMnemonic(entropy)
+	if err != nil {
+		t.Fatalf("failed to create mnemonic: %v", err)
+	}
+	expected := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	if mnemonic != expected {
+		t.Errorf("expected %s but got %s", expected, mnemonic)
+	}
+	seed := hex.EncodeToString(MnemonicToSeed(mnemonic, "TREZOR"))
+	if seed != "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04" {
+		t.Errorf("unexpected seed %s", seed)
+	}
+
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		entropy, _ := NewEntropy(bits)
+		mnemonic, _ := NewMnemonic(entropy)
+		decoded, err := MnemonicToEntropy(mnemonic)
+		if err != nil || hex.EncodeToString(decoded) != hex.EncodeToString(entropy) {
+			t.Errorf("decoding %d bits mnemonic should give back the entropy: %v", bits, err)
+		}
+	}
+
+	if err := ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"); err != errorMnemonicChecksum {
+		t.Errorf("expected %v but got %v", errorMnemonicChecksum, err)
+	}
+	if err := ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon simcoin"); err != errorInvalidMnemonic {
+		t.Errorf("expected %v but got %v", errorInvalidMnemonic, err)
+	}
+	if _, err := NewEntropy(100); err != errorInvalidEntropy {
+		t.Errorf("expected %v but got %v", errorInvalidEntropy, err)
+	}
+}
@@ -0,0 +1,189 @@
+package sc
+
+import (
+	"errors"
+	"time"
+)
+
+// maxDifficulty the number of hex digits of a block hash
+const maxDifficulty = 64
+
+var errorInvalidHalvingInterval = errors.New("error: halving interval must be positive")
+var errorInvalidDifficulty = errors.New("error: difficulty must be between 0 and 64")
+var errorInvalidBlockLimits = errors.New("error: max block size and max block transactions must be positive")
+var errorInvalidMedianTimeSpan = errors.New("error: median time span must be positive")
+var errorInvalidSubsidy = errors.New("error: initial subsidy must be positive and at most the maximum amount")
+var errorInvalidCoinbaseMaturity = errors.New("error: coinbase maturity must not be negative")
+var errorInvalidDataCarrierSize = errors.New("error: max data carrier size must not be negative")
+var errorNoPoWer = errors.New("error: proof of work algorithm is missing")
+
+// the first byte of the addresses of the main network and of the test networks
+const mainNetAddressVersion = byte(0x00)
+const testNetAddressVersion = byte(0x6f)
+
+// ChainParams the consensus rules of a network. Every blockchain follows its own parameters
+// so chains of different networks can run side by side in one process
+type ChainParams struct {
+	Name string
+	// Magic identify the messages of the network on the wire
+	Magic [4]byte
+	// AddressVersion the first byte of the addresses of the network
+	AddressVersion byte
+
+	// the genesis block is the same on every node: its coinbase pays GenesisAddress and carries
+	// GenesisMessage, and it is mined at GenesisTime with GenesisNonce. Its hash must be GenesisHash
+	GenesisAddress Address
+	GenesisMessage string
+	GenesisTime    time.Time
+	GenesisNonce   int
+	GenesisHash    Hash
+
+	// reward schedule: the block subsidy starts at InitialSubsidy and is halved every HalvingInterval
+	// blocks. Once it drops below MinSubsidyUnit no more coins are created
+	InitialSubsidy   Amount
+	HalvingInterval  int
+	MinSubsidyUnit   Amount
+	CoinbaseMaturity int
+
+	// Difficulty the number of leading zeros of the block hashes, found by PoWer
+	Difficulty         int
+	PoWer              PoWer
+	MedianTimeSpan     int
+	MaxFutureBlockTime time.Duration
+
+	MaxBlockSize         int
+	MaxBlockTransactions int
+	MaxDataCarrierSize   int
+}
+
+// MainNet return the parameters of the main network
+func MainNet() *ChainParams {
+	return &ChainParams{
+		Name:                 "mainnet",
+		Magic:                [4]byte{0x73, 0x69, 0x6d, 0x6d},
+		AddressVersion:       mainNetAddressVersion,
+		GenesisAddress:       Address("1NHXs8UxcgHzDNxWNTcYjKv8MGY72rnbbE"),
+		GenesisMessage:       "simcoin 03/Jan/2018 a simple bitcoin demonstration",
+		GenesisTime:          time.Date(2018, time.January, 3, 18, 15, 5, 0, time.UTC),
+		GenesisNonce:         259,
+		GenesisHash:          StringToHash("0030e42e4b8475b5d5b69a1556c6045f46465a2fc9155a3fbfbca6bc9a04c8e1"),
+		InitialSubsidy:       5 * Coin,
+		HalvingInterval:      210000,
+		MinSubsidyUnit:       Shatoshi,
+		CoinbaseMaturity:     100,
+		Difficulty:           2,
+		PoWer:                NewSimPow(),
+		MedianTimeSpan:       11,
+		MaxFutureBlockTime:   2 * time.Hour,
+		MaxBlockSize:         1000000,
+		MaxBlockTransactions: 10000,
+		MaxDataCarrierSize:   80,
+	}
+}
+
+// TestNet return the parameters of the public test network: same rules as the main network with
+// their own addresses
+func TestNet() *ChainParams {
+	return &ChainParams{
+		Name:                 "testnet",
+		Magic:                [4]byte{0x73, 0x69, 0x6d, 0x74},
+		AddressVersion:       testNetAddressVersion,
+		GenesisAddress:       Address("n2oVABZwRhjEzVS862avZF8TDG8ouJnNc4"),
+		GenesisMessage:       "simcoin testnet",
+		GenesisTime:          time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC),
+		GenesisNonce:         553,
+		GenesisHash:          StringToHash("008741051a9a6dabea064eb4ca1739615ae91c89826f1de6b60a5bcf2799e63b"),
+		InitialSubsidy:       5 * Coin,
+		HalvingInterval:      210000,
+		MinSubsidyUnit:       Shatoshi,
+		CoinbaseMaturity:     100,
+		Difficulty:           2,
+		PoWer:                NewSimPow(),
+		MedianTimeSpan:       11,
+		MaxFutureBlockTime:   2 * time.Hour,
+		MaxBlockSize:         1000000,
+		MaxBlockTransactions: 10000,
+		MaxDataCarrierSize:   80,
+	}
+}
+
+// RegTest return the parameters of a local network for development: blocks are found instantly
+// and the subsidy halves quickly
+func RegTest() *ChainParams {
+	return &ChainParams{
+		Name:                 "regtest",
+		Magic:                [4]byte{0x73, 0x69, 0x6d, 0x72},
+		AddressVersion:       testNetAddressVersion,
+		GenesisAddress:       Address("n2oVABZwRhjEzVS862avZF8TDG8ouJnNc4"),
+		GenesisMessage:       "simcoin regtest",
+		GenesisTime:          time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC),
+		GenesisNonce:         7,
+		GenesisHash:          StringToHash("04c61c2b7a408644bd48f90ef7ce9002573ead49c09340f21449c54968342e91"),
+		InitialSubsidy:       5 * Coin,
+		HalvingInterval:      150,
+		MinSubsidyUnit:       Shatoshi,
+		CoinbaseMaturity:     100,
+		Difficulty:           1,
+		PoWer:                NewSimPow(),
+		MedianTimeSpan:       11,
+		MaxFutureBlockTime:   2 * time.Hour,
+		MaxBlockSize:         1000000,
+		MaxBlockTransactions: 10000,
+		MaxDataCarrierSize:   80,
+	}
+}
+
+// Address return the address of the given account on the network
+func (p *ChainParams) Address(acc *Account) Address {
+	return pubKeyToAddress(acc.PubKey, p.AddressVersion)
+}
+
+// ValidateAddress check if the address is valid and belongs to the network
+func (p *ChainParams) ValidateAddress(address string) bool {
+	payload := DecodeBase58(address)
+	return ValidateAddress(address) && payload[0] == p.AddressVersion
+}
+
+// validate check the parameters describe a network a chain can run on
+func (p *ChainParams) validate() error {
+	if p.HalvingInterval <= 0 {
+		return errorInvalidHalvingInterval
+	}
+	if p.Difficulty < 0 || p.Difficulty > maxDifficulty {
+		return errorInvalidDifficulty
+	}
+	if p.MaxBlockSize <= 0 || p.MaxBlockTransactions <= 0 {
+		return errorInvalidBlockLimits
+	}
+	if p.MedianTimeSpan <= 0 {
+		return errorInvalidMedianTimeSpan
+	}
+	if p.InitialSubsidy <= 0 || p.InitialSubsidy > MaxAmount {
+		return errorInvalidSubsidy
+	}
+	if p.CoinbaseMaturity < 0 {
+		return errorInvalidCoinbaseMaturity
+	}
+	if p.MaxDataCarrierSize < 0 {
+		return errorInvalidDataCarrierSize
+	}
+	if p.PoWer == nil {
+		return errorNoPoWer
+	}
+	return nil
+}
+
+// isMature return true if a coinbase output with the given number of confirmations can be spent in the next block
+func (p *ChainParams) isMature(confirmations int) bool {
+	return confirmations >= p.CoinbaseMaturity
+}
+
+// newBlock return a new block with the given transactions, to be mined at the difficulty of the network
+func (p *ChainParams) newBlock(transactions []*Transaction, prevHash Hash) *Block {
+	return &Block{
+		Transactions: transactions,
+		PrevHash:     prevHash,
+		Timestamp:    time.Now(),
+		Difficulty:   p.Difficulty,
+	}
+}
@@ -0,0 +1,116 @@
+package sc
+
+import (
+	"testing"
+)
+
+func TestIndependentChains(t *testing.T) {
+	miner := NewAccount()
+	mainDB, _ := NewMemDatabase()
+	regDB, _ := NewMemDatabase()
+	main := NewBlockchain(MainNet(), miner, mainDB)
+	reg := NewBlockchain(testParams, miner, regDB)
+
+	main.Mine(1)
+	reg.Mine(3)
+	if main.Height() != 1 || reg.Height() != 3 {
+		t.Errorf("expected heights 1 and 3 but got %d and %d", main.Height(), reg.Height())
+	}
+	b, _ := reg.GetBlockByHeight(3)
+	if b.Difficulty != testParams.Difficulty {
+		t.Errorf("expected difficulty %d but got %d", testParams.Difficulty, b.Difficulty)
+	}
+
+	// a block mined under the rules of another network is rejected
+	prev := main.getBlock(lastBlockKey)
+	b = testParams.newBlock([]*Transaction{NewCoinbase(main.ScriptPubKey(miner.GetAddress()), MainNet().Subsidy(2))}, prev.CalHash())
+	b.Nonce = testParams.PoWer.Work(b)
+	if err := main.validateBlock(b); err != errorWrongDifficulty {
+		t.Errorf("expected %v but got %v", errorWrongDifficulty, err)
+	}
+}
+
+func TestInvalidParams(t *testing.T) {
+	for _, c := range []struct {
+		change func(p *ChainParams)
+		err    error
+	}{
+		{func(p *ChainParams) { p.HalvingInterval = 0 }, errorInvalidHalvingInterval},
+		{func(p *ChainParams) { p.Difficulty = -1 }, errorInvalidDifficulty},
+		{func(p *ChainParams) { p.Difficulty = maxDifficulty + 1 }, errorInvalidDifficulty},
+		{func(p *ChainParams) { p.MaxBlockSize = 0 }, errorInvalidBlockLimits},
+		{func(p *ChainParams) { p.MaxBlockTransactions = -1 }, errorInvalidBlockLimits},
+		{func(p *ChainParams) { p.MedianTimeSpan = 0 }, errorInvalidMedianTimeSpan},
+		{func(p *ChainParams) { p.InitialSubsidy = 0 }, errorInvalidSubsidy},
+		{func(p *ChainParams) { p.InitialSubsidy = -Coin }, errorInvalidSubsidy},
+		{func(p *ChainParams) { p.InitialSubsidy = MaxAmount + 1 }, errorInvalidSubsidy},
+		{func(p *ChainParams) { p.CoinbaseMaturity = -1 }, errorInvalidCoinbaseMaturity},
+		{func(p *ChainParams) { p.MaxDataCarrierSize = -1 }, errorInvalidDataCarrierSize},
+		{func(p *ChainParams) { p.PoWer = nil }, errorNoPoWer},
+	} {
+		params := *testParams
+		c.change(&params)
+		db, _ := NewMemDatabase()
+		if _, err := OpenBlockchain(&params, NewAccount(), db); err != c.err {
+			t.Errorf("expected %v but got %v", c.err, err)
+		}
+	}
+}
+
+func TestNetworkAddress(t *testing.T) {
+	acc := NewAccount()
+	mainAddr := MainNet().Address(acc)
+	testAddr := TestNet().Address(acc)
+	if mainAddr.String() != acc.GetAddress().String() {
+		t.Errorf("expected the main network address by default")
+	}
+	if mainAddr.String() == testAddr.String() {
+		t.Errorf("addresses of different networks should differ")
+	}
+	if !TestNet().ValidateAddress(testAddr.String()) || TestNet().ValidateAddress(mainAddr.String()) || !MainNet().ValidateAddress(mainAddr.String()) {
+		t.Errorf("addresses should only be valid on their own network")
+	}
+	if TestNet().ValidateAddress("nope") {
+		t.Errorf("invalid address should be refused")
+	}
+	for _, p := range []*ChainParams{MainNet(), TestNet(), RegTest()} {
+		if !p.ValidateAddress(p.GenesisAddress.String()) {
+			t.Errorf("%s genesis should pay an address of its network", p.Name)
+		}
+	}
+
+	// a chain refuses the addresses of another network
+	db, _ := NewMemDatabase()
+	reg := NewBlockchain(testParams, acc, db)
+	reg.Mine(1)
+	if err := reg.NewTxBuilder(acc).AddOutput(mainAddr, Coin); err != errorInvalidAddress {
+		t.Errorf("expected %v but got %v", errorInvalidAddress, err)
+	}
+	if _, _, err := reg.BalanceOf(mainAddr); err != errorInvalidAddress {
+		t.Errorf("expected %v but got %v", errorInvalidAddress, err)
+	}
+	tx := &Transaction{
+		Vin:  []TxIn{{Txid: hash256([]byte("coin")), Vout: 0}},
+		Vout: []TxOut{{Value: Coin, ScriptPubKey: reg.ScriptPubKey(mainAddr)}},
+	}
+	if err := tx.CheckSanity(testParams); err != errorInvalidScript {
+		t.Errorf("expected %v but got %v", errorInvalidScript, err)
+	}
+}
+
+func TestNetworkParams(t *testing.T) {
+	// every call return its own copy of the parameters
+	p := RegTest()
+	p.Difficulty = maxDifficulty
+	p.GenesisHash[0]++
+	if fresh := RegTest(); fresh.Difficulty == maxDifficulty || fresh.GenesisHash[0] == p.GenesisHash[0] {
+		t.Errorf("changing parameters should not change the network")
+	}
+	magics := make(map[[4]byte]string)
+	for _, p := range []*ChainParams{MainNet(), TestNet(), RegTest()} {
+		if other, ok := magics[p.Magic]; ok {
+			t.Errorf("%s and %s share the same magic", p.Name, other)
+		}
+		magics[p.Magic] = p.Name
+	}
+}
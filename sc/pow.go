@@ -35,7 +35,7 @@ func (pow *SimPow) data(block *Block, nonce int) []byte {
 // contains same # of leading zero as the target difficulty
 func (pow *SimPow) Work(block *Block) int {
 	nonce := 0
-	prefix := strings.Repeat("0", difficulty)
+	prefix := strings.Repeat("0", block.Difficulty)
 	for {
 		v := hex.EncodeToString(hash256(pow.data(block, nonce)))
 		if strings.HasPrefix(v, prefix) {
@@ -0,0 +1,31 @@
+package sc
+
+// Subsidy return the maximum number of new coins the coinbase of the block at the given height can create
+func (p *ChainParams) Subsidy(height int) Amount {
+	halvings := uint(height / p.HalvingInterval)
+	if halvings >= 63 {
+		return 0
+	}
+	subsidy := p.InitialSubsidy >> halvings
+	if subsidy < p.MinSubsidyUnit {
+		return 0
+	}
+	return subsidy
+}
+
+// TotalSupply return the total number of coins created from the genesis up to the block at the given height
+func (p *ChainParams) TotalSupply(height int) Amount {
+	total := Amount(0)
+	for start := 0; start <= height; start += p.HalvingInterval {
+		subsidy := p.Subsidy(start)
+		if subsidy == 0 {
+			break
+		}
+		blocks := p.HalvingInterval
+		if start+blocks > height+1 {
+			blocks = height + 1 - start
+		}
+		total += subsidy * Amount(blocks)
+	}
+	return total
+}
@@ -0,0 +1,48 @@
+package sc
+
+import "testing"
+
+func TestSubsidyHalving(t *testing.T) {
+	params := &ChainParams{InitialSubsidy: 8, HalvingInterval: 2, MinSubsidyUnit: 2}
+
+	expected := []Amount{8, 8, 4, 4, 2, 2, 0, 0}
+	for height, v := range expected {
+		assertEquals(t, "subsidy", v, params.Subsidy(height))
+	}
+	assertEquals(t, "total supply", 8, params.TotalSupply(0))
+	assertEquals(t, "total supply", 20, params.TotalSupply(2))
+	assertEquals(t, "total supply", 28, params.TotalSupply(5))
+	assertEquals(t, "total supply", 28, params.TotalSupply(1000))
+}
+
+func TestCoinbaseFollowsSchedule(t *testing.T) {
+	params := *testParams
+	params.InitialSubsidy, params.HalvingInterval, params.MinSubsidyUnit = 8, 2, 1
+	// the genesis pays the initial subsidy so the network needs its own
+	genesis := params.GenesisBlock()
+	params.GenesisNonce = params.PoWer.Work(genesis)
+	genesis.Nonce = params.GenesisNonce
+	params.GenesisHash = genesis.CalHash()
+
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(&params, miner, db)
+	w := NewMemWallet(blockchain)
+	w.Add("miner", miner)
+
+	blockchain.Mine(4)
+	// blocks at height 1..4 pay 8, 4, 4, 2
+	assertEquals(t, "miner", 18, w.Balance("miner"))
+
+	prev := blockchain.getBlock(lastBlockKey)
+	b := params.newBlock([]*Transaction{NewCoinbase(blockchain.ScriptPubKey(testParams.Address(miner)), 2)}, prev.CalHash())
+	b.Nonce = params.PoWer.Work(b)
+	if !blockchain.isValidBlock(b) {
+		t.Errorf("block claiming the subsidy should be valid")
+	}
+	b = params.newBlock([]*Transaction{NewCoinbase(blockchain.ScriptPubKey(testParams.Address(miner)), 3)}, prev.CalHash())
+	b.Nonce = params.PoWer.Work(b)
+	if blockchain.isValidBlock(b) {
+		t.Errorf("block claiming more than the subsidy should be rejected")
+	}
+}
@@ -3,13 +3,24 @@ package sc
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
+var errorNoInputs = errors.New("error: transaction has no inputs")
+var errorNoOutputs = errors.New("error: transaction has no outputs")
+var errorTxTooLarge = errors.New("error: transaction is larger than the maximum block size")
+var errorValueOutOfRange = errors.New("error: transaction output value is negative or too large")
+var errorInvalidInput = errors.New("error: transaction input refers to an invalid output")
+var errorDuplicateInput = errors.New("error: this guy is trying to spend the same money twice")
+var errorInvalidScript = errors.New("error: transaction output script is neither a payment to an address nor a data carrier")
+var errorInvalidTransaction = errors.New("error: invalid serialized transaction")
+
 // TxOut transaction output
 type TxOut struct {
-	Value        int
+	Value        Amount
 	ScriptPubKey string
 }
 
@@ -32,9 +43,123 @@ func (txIn *TxIn) IsCoinBase() bool {
 	return txIn.Vout == -1
 }
 
-// CanUnlock check if the transaction input can unlock the given output
-func (txIn *TxIn) CanUnlock(txOut TxOut) bool {
-	return verifyOwnership(txIn.ScriptSig, txOut.ScriptPubKey)
+// CheckSanity check the transaction is well formed under the rules of the network without looking at the chain
+func (tx *Transaction) CheckSanity(params *ChainParams) error {
+	if len(tx.Vin) == 0 {
+		return errorNoInputs
+	}
+	if len(tx.Vout) == 0 {
+		return errorNoOutputs
+	}
+	if tx.Size() > params.MaxBlockSize {
+		return errorTxTooLarge
+	}
+	total := Amount(0)
+	for _, vout := range tx.Vout {
+		var err error
+		if total, err = total.Add(vout.Value); err != nil {
+			return errorValueOutOfRange
+		}
+		if !vout.isStandard(params) {
+			return errorInvalidScript
+		}
+		if vout.IsDataCarrier() && len(vout.Data()) > params.MaxDataCarrierSize {
+			return errorDataTooLarge
+		}
+	}
+	if tx.IsCoinBase() {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, vin := range tx.Vin {
+		if vin.IsCoinBase() || vin.Vout < 0 {
+			return errorInvalidInput
+		}
+		key := fmt.Sprintf("%s:%d", vin.Txid, vin.Vout)
+		if seen[key] {
+			return errorDuplicateInput
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// IsCoinBase return true if the transaction is a coinbase transaction
+func (tx *Transaction) IsCoinBase() bool {
+	return len(tx.Vin) == 1 && tx.Vin[0].IsCoinBase()
+}
+
+// CanUnlock check if the input idx of the transaction can unlock the given output
+func (tx *Transaction) CanUnlock(idx int, prevOut TxOut) bool {
+	return verifyOwnership(tx.Vin[idx].ScriptSig, prevOut.ScriptPubKey, tx.sigHash(idx, prevOut))
+}
+
+// sigHash return the digest signed by the input idx. It covers the inputs and the outputs of the
+// transaction and the output being spent, but neither the scriptSigs nor the ID
+func (tx *Transaction) sigHash(idx int, prevOut TxOut) Hash {
+	stripped := Transaction{Vin: make([]TxIn, len(tx.Vin)), Vout: tx.Vout}
+	for i, vin := range tx.Vin {
+		stripped.Vin[i] = TxIn{Txid: vin.Txid, Vout: vin.Vout}
+	}
+	return hash256(bytes.Join([][]byte{toBytes(stripped), toBytes(idx), toBytes(prevOut)}, []byte{}))
+}
+
+// isStandard return true if the output pays to a valid address of the network with a P2PKH script
+// or carries hex data after OP_RETURN
+func (txOut *TxOut) isStandard(params *ChainParams) bool {
+	ops := strings.Fields(txOut.ScriptPubKey)
+	if len(ops) == 5 {
+		return ops[0] == "OP_DUP" && ops[1] == "OP_HASH160" && params.ValidateAddress(ops[2]) &&
+			ops[3] == "OP_EQUALVERIFY" && ops[4] == "OP_CHECKSIG"
+	}
+	if len(ops) > 0 && ops[0] == "OP_RETURN" {
+		if len(ops) == 1 {
+			return true
+		}
+		_, err := hex.DecodeString(ops[1])
+		return len(ops) == 2 && err == nil
+	}
+	return false
+}
+
+// IsDataCarrier return true if the output is an unspendable OP_RETURN output
+func (txOut *TxOut) IsDataCarrier() bool {
+	return strings.HasPrefix(txOut.ScriptPubKey, "OP_RETURN")
+}
+
+// Data return the data carried by an OP_RETURN output
+func (txOut *TxOut) Data() []byte {
+	ops := strings.Fields(txOut.ScriptPubKey)
+	if len(ops) != 2 {
+		return []byte{}
+	}
+	data, err := hex.DecodeString(ops[1])
+	if err != nil {
+		return []byte{}
+	}
+	return data
+}
+
+// TxInfo a confirmed transaction and where it is in the chain
+type TxInfo struct {
+	Tx            *Transaction
+	BlockHash     Hash
+	Height        int
+	Confirmations int
+}
+
+// Serialize return the bytes of the transaction for sending it to another node
+func (tx *Transaction) Serialize() []byte {
+	return toBytes(tx)
+}
+
+// DeserializeTransaction return the transaction from the bytes made by Transaction.Serialize
+func DeserializeTransaction(data []byte) (*Transaction, error) {
+	var tx *Transaction
+	if err := toObject(data, &tx); err != nil || tx == nil {
+		return nil, errorInvalidTransaction
+	}
+	return tx, nil
 }
 
 // CalHash return hash of the transaction
@@ -47,15 +172,29 @@ func (tx *Transaction) SetID() {
 	tx.ID = hash256(bytes.Join([][]byte{toBytes(tx), []byte(time.Now().String())}, []byte{}))
 }
 
-// NewCoinbase return a coinbase transaction
-func NewCoinbase(to string) *Transaction {
+// Size return the size in bytes of the serialized transaction
+func (tx *Transaction) Size() int {
+	return len(toBytes(tx))
+}
+
+// OutputValue return the total value of the transaction outputs
+func (tx *Transaction) OutputValue() Amount {
+	total := Amount(0)
+	for _, vout := range tx.Vout {
+		total += vout.Value
+	}
+	return total
+}
+
+// NewCoinbase return a coinbase transaction paying the given value to the miner
+func NewCoinbase(to string, value Amount) *Transaction {
 	txIn := TxIn{
 		Txid:      []byte{},
 		Vout:      -1,
 		ScriptSig: []byte(""),
 	}
 	txOut := TxOut{
-		Value:        reward,
+		Value:        value,
 		ScriptPubKey: to,
 	}
 
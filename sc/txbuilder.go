@@ -0,0 +1,255 @@
+package sc
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// maxFeeRounds number of times the builder re-estimates the fee for the fee rate
+const maxFeeRounds = 10
+
+var errorUnknownInput = errors.New("error: input doesn't belong to the sending accounts")
+var errorInvalidPayout = errors.New("error: invalid payout, expected <address>,<amount>")
+var errorFeeRateTooHigh = errors.New("error: fee rate times the transaction size is larger than the maximum amount")
+var errorFeeNotSettled = errors.New("error: fee for the fee rate did not settle")
+
+// TxBuilder build a transaction paying many recipients with the money of the given accounts.
+// The transaction can be inspected before it is signed and broadcast
+type TxBuilder struct {
+	bc       *Blockchain
+	from     []*Account
+	outputs  []TxOut
+	change   Address
+	fixedFee Amount
+	feeRate  Amount
+	fee      Amount
+	selector CoinSelector
+	owners   map[outPoint]ownedOutput
+	stats    SelectionStats
+}
+
+// NewTxBuilder return a builder spending the money of the given accounts. By default the change goes
+// back to the first account, no fee is paid and the largest outputs are spent first
+func (bc *Blockchain) NewTxBuilder(from ...*Account) *TxBuilder {
+	return &TxBuilder{
+		bc:       bc,
+		from:     from,
+		outputs:  make([]TxOut, 0),
+		selector: LargestFirst{},
+		owners:   make(map[outPoint]ownedOutput),
+	}
+}
+
+// AddOutput pay the given amount to the given address
+func (b *TxBuilder) AddOutput(to Address, amount Amount) error {
+	if !b.bc.params.ValidateAddress(string(to)) {
+		return errorInvalidAddress
+	}
+	if amount <= 0 || !amount.IsValid() {
+		return errorAmountOutOfRange
+	}
+	b.outputs = append(b.outputs, TxOut{
+		Value:        amount,
+		ScriptPubKey: b.bc.ScriptPubKey(to),
+	})
+	return nil
+}
+
+// SetChange send the change to the given address instead of the first account
+func (b *TxBuilder) SetChange(address Address) error {
+	if !b.bc.params.ValidateAddress(string(address)) {
+		return errorInvalidAddress
+	}
+	b.change = address
+	return nil
+}
+
+// SetFee pay a fixed fee to the miner
+func (b *TxBuilder) SetFee(fee Amount) error {
+	if !fee.IsValid() {
+		return errorAmountOutOfRange
+	}
+	b.fixedFee = fee
+	return nil
+}
+
+// SetFeeRate pay the given amount per byte of the signed transaction to the miner.
+// The fee is at least the fixed fee
+func (b *TxBuilder) SetFeeRate(rate Amount) error {
+	if !rate.IsValid() {
+		return errorAmountOutOfRange
+	}
+	b.feeRate = rate
+	return nil
+}
+
+// SetCoinSelector pick the inputs with the given coin selector, or spend the largest outputs first if nil
+func (b *TxBuilder) SetCoinSelector(selector CoinSelector) {
+	if selector == nil {
+		selector = LargestFirst{}
+	}
+	b.selector = selector
+}
+
+// Fee return the fee paid by the last built transaction
+func (b *TxBuilder) Fee() Amount {
+	return b.fee
+}
+
+// Stats return how many inputs the last built transaction spends and its change
+func (b *TxBuilder) Stats() SelectionStats {
+	return b.stats
+}
+
+// Build return the unsigned transaction paying the outputs. When a fee rate is set the fee is
+// estimated from the size of the signed transaction
+func (b *TxBuilder) Build() (*Transaction, error) {
+	if len(b.from) == 0 {
+		return nil, errorNoInputs
+	}
+	if len(b.outputs) == 0 {
+		return nil, errorNoOutputs
+	}
+	amount := Amount(0)
+	for _, out := range b.outputs {
+		var err error
+		if amount, err = amount.Add(out.Value); err != nil {
+			return nil, err
+		}
+	}
+	utxos := make([]UTXO, 0)
+	b.bc.lock.RLock()
+	for _, acc := range b.from {
+		_, _, accUTXOs := b.bc.unspentOf(hash160(acc.PubKey))
+		for _, u := range accUTXOs {
+			b.owners[outPoint{u.TxIn.Txid.String(), u.TxIn.Vout}] = ownedOutput{acc: acc, out: TxOut{Value: u.Value, ScriptPubKey: u.ScriptPubKey}}
+		}
+		utxos = append(utxos, accUTXOs...)
+	}
+	b.bc.lock.RUnlock()
+	fee := b.fixedFee
+	for round := 0; round < maxFeeRounds; round++ {
+		tx, err := b.build(utxos, amount, fee)
+		if err != nil {
+			return nil, err
+		}
+		if b.feeRate == 0 {
+			return tx, nil
+		}
+		// the signatures have a fixed size so signing a copy tells the final size
+		signed := *tx
+		signed.Vin = append([]TxIn{}, tx.Vin...)
+		if err := b.Sign(&signed); err != nil {
+			return nil, err
+		}
+		size := Amount(signed.Size())
+		if b.feeRate > MaxAmount/size {
+			return nil, errorFeeRateTooHigh
+		}
+		required := b.feeRate * size
+		if required <= fee {
+			return tx, nil
+		}
+		fee = required
+	}
+	return nil, errorFeeNotSettled
+}
+
+// build select the inputs paying the amount and the fee and add the change output
+func (b *TxBuilder) build(utxos []UTXO, amount Amount, fee Amount) (*Transaction, error) {
+	required, err := amount.Add(fee)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := b.selector.Select(utxos, required)
+	if err != nil {
+		return nil, err
+	}
+	stats := newSelectionStats(selected, required)
+	if stats.Total < required {
+		return nil, errorNotEnoughMoney
+	}
+	vins := make([]TxIn, 0, len(selected))
+	for _, u := range selected {
+		vins = append(vins, u.TxIn)
+	}
+	vouts := append([]TxOut{}, b.outputs...)
+	if stats.Change > 0 {
+		change := b.change
+		if change == nil {
+			change = b.bc.params.Address(b.from[0])
+		}
+		vouts = append(vouts, TxOut{
+			Value:        stats.Change,
+			ScriptPubKey: b.bc.ScriptPubKey(change),
+		})
+	}
+	tx := &Transaction{
+		Vin:  vins,
+		Vout: vouts,
+	}
+	tx.SetID()
+	b.fee = fee
+	b.stats = stats
+	return tx, nil
+}
+
+// Sign sign the inputs of the transaction with the keys of the accounts owning them.
+// The transaction gets a new ID
+func (b *TxBuilder) Sign(tx *Transaction) error {
+	return b.bc.signInputs(tx, b.owners)
+}
+
+// BuildSigned return the signed transaction paying the outputs, ready to be broadcast
+func (b *TxBuilder) BuildSigned() (*Transaction, error) {
+	tx, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Sign(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// SendTransaction validate the signed transaction against the tip and mine it into a new block.
+// It return why the transaction was left out of the block if it was
+func (bc *Blockchain) SendTransaction(tx *Transaction) error {
+	if _, err := bc.Fee(tx); err != nil {
+		bc.events.publish(Event{Type: TransactionRejected, Tx: tx, Err: err})
+		return err
+	}
+	return bc.MineNewBlock([]*Transaction{tx})
+}
+
+// ReadPayouts read the payouts from CSV lines of <address>,<amount> like "1Abc...,1.5".
+// A header line starting with "address" is skipped
+func ReadPayouts(r io.Reader) ([]Address, []Amount, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, errorInvalidPayout
+	}
+	addresses := make([]Address, 0, len(records))
+	amounts := make([]Amount, 0, len(records))
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+		address := Address(strings.TrimSpace(record[0]))
+		if !ValidateAddress(string(address)) {
+			return nil, nil, errorInvalidAddress
+		}
+		amount, err := ParseAmount(record[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		addresses = append(addresses, address)
+		amounts = append(amounts, amount)
+	}
+	return addresses, amounts, nil
+}
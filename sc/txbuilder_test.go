@@ -0,0 +1,139 @@
+package sc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTxBuilderMultipleOutputs(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	bob := NewAccount()
+	change := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+
+	builder := blockchain.NewTxBuilder(miner)
+	builder.AddOutput(testParams.Address(alice), 1*Coin)
+	builder.AddOutput(testParams.Address(bob), 2*Coin)
+	builder.SetChange(testParams.Address(change))
+	builder.SetFeeRate(10)
+	tx, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Vout) != 3 || tx.Vin[0].ScriptSig != nil {
+		t.Fatalf("expected an unsigned transaction with 3 outputs")
+	}
+	if _, err := blockchain.Fee(tx); err == nil {
+		t.Errorf("unsigned transaction should be rejected")
+	}
+	if err := builder.Sign(tx); err != nil {
+		t.Fatal(err)
+	}
+	fee, err := blockchain.Fee(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "fee", fee, builder.Fee())
+	if fee < 10*Amount(tx.Size()) {
+		t.Errorf("fee %s is lower than the fee rate", fee)
+	}
+	if err := blockchain.SendTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	available, _ := blockchain.Balance(alice)
+	assertEquals(t, "alice", available, 1*Coin)
+	available, _ = blockchain.Balance(bob)
+	assertEquals(t, "bob", available, 2*Coin)
+	available, _ = blockchain.Balance(change)
+	assertEquals(t, "change", available, builder.Stats().Change)
+}
+
+func TestTxBuilderErrors(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+
+	builder := blockchain.NewTxBuilder(miner)
+	if err := builder.AddOutput(Address("nope"), Coin); err != errorInvalidAddress {
+		t.Errorf("expected invalid address but got %v", err)
+	}
+	if _, err := builder.Build(); err != errorNoOutputs {
+		t.Errorf("expected no outputs but got %v", err)
+	}
+	builder.AddOutput(testParams.Address(NewAccount()), testParams.Subsidy(1)+1)
+	if _, err := builder.Build(); err != errorNotEnoughMoney {
+		t.Errorf("expected not enough money but got %v", err)
+	}
+
+	builder = blockchain.NewTxBuilder(miner)
+	builder.AddOutput(testParams.Address(NewAccount()), Coin)
+	builder.SetFeeRate(MaxAmount)
+	if _, err := builder.Build(); err != errorFeeRateTooHigh {
+		t.Errorf("expected fee rate too high but got %v", err)
+	}
+}
+
+func TestSendTransactionLeftOut(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	params := *testParams
+	blockchain := NewBlockchain(&params, miner, db)
+	blockchain.Mine(1)
+
+	builder := blockchain.NewTxBuilder(miner)
+	builder.AddOutput(params.Address(NewAccount()), Coin)
+	tx, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.Sign(tx)
+	// the transaction is valid but doesn't fit next to the coinbase
+	coinbase := NewCoinbase(blockchain.ScriptPubKey(params.Address(miner)), params.InitialSubsidy)
+	params.MaxBlockSize = len(toBytes(Block{})) + coinbase.Size() + tx.Size() - 1
+	height := blockchain.Height()
+	if err := blockchain.SendTransaction(tx); err != errorBlockFull {
+		t.Errorf("expected %v but got %v", errorBlockFull, err)
+	}
+	if _, err := blockchain.GetTransaction(tx.ID); err != errorTxNotFound {
+		t.Errorf("left out transaction should not be in the chain")
+	}
+	if blockchain.Height() != height+1 {
+		t.Errorf("the block should be mined without the transaction")
+	}
+}
+
+func TestSendCSV(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	bob := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+	wallet := NewMemWallet(blockchain)
+	wallet.Add("miner", miner)
+
+	payouts := fmt.Sprintf("address,amount\n%s,1.5\n%s, 0.25\n", testParams.Address(alice), testParams.Address(bob))
+	tx, err := wallet.SendCSV("miner", strings.NewReader(payouts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Vout) != 3 {
+		t.Errorf("expected 2 payouts and the change but got %d outputs", len(tx.Vout))
+	}
+	available, _ := blockchain.Balance(alice)
+	assertEquals(t, "alice", available, 1*Coin+Coin/2)
+	available, _ = blockchain.Balance(bob)
+	assertEquals(t, "bob", available, Coin/4)
+
+	if _, err := wallet.SendCSV("miner", strings.NewReader("nope,1\n")); err != errorInvalidAddress {
+		t.Errorf("expected invalid address but got %v", err)
+	}
+	if _, err := wallet.SendCSV("miner", strings.NewReader("just one field\n")); err != errorInvalidPayout {
+		t.Errorf("expected invalid payout but got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package sc
+
+import (
+	"bytes"
+)
+
+// txIndexKey is set when the transaction index is enabled
+var txIndexKey = []byte("txindex")
+var txLocationPrefix = []byte("tx-")
+
+// txLocation where a transaction is in the chain
+type txLocation struct {
+	BlockHash Hash
+	Pos       int
+}
+
+func txLocationKey(txid Hash) []byte {
+	return append(append([]byte{}, txLocationPrefix...), txid...)
+}
+
+// HasTxIndex return true if the transactions are indexed by id
+func (bc *Blockchain) HasTxIndex() bool {
+	ok, _ := bc.db.Has(txIndexKey)
+	return ok
+}
+
+// EnableTxIndex index the transactions of the chain by id and keep the index up to date
+// as blocks are added. The setting is stored in the database
+func (bc *Blockchain) EnableTxIndex() {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	if bc.HasTxIndex() {
+		return
+	}
+	bc.rebuildTxIndex()
+}
+
+// RebuildTxIndex index all transactions of the active chain, e.g. for a database created
+// before the index was enabled
+func (bc *Blockchain) RebuildTxIndex() {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	bc.rebuildTxIndex()
+}
+
+func (bc *Blockchain) rebuildTxIndex() {
+	it := bc.chainIterator(0)
+	for b := it.Next(); b != nil; b = it.Next() {
+		bc.indexTransactions(b)
+	}
+	bc.db.Put(txIndexKey, []byte{1})
+}
+
+// updateIndexes remove the blocks which left the active chain from the indexes and add the blocks which joined it
+func (bc *Blockchain) updateIndexes(disconnected []*Block, connected []*Block) {
+	if bc.HasTxIndex() {
+		for _, b := range disconnected {
+			for _, tx := range b.Transactions {
+				bc.db.Delete(txLocationKey(tx.ID))
+			}
+		}
+		for _, b := range connected {
+			bc.indexTransactions(b)
+		}
+	}
+	if bc.HasAddressIndex() {
+		for _, b := range disconnected {
+			bc.disconnectAddresses(b)
+		}
+		for _, b := range connected {
+			bc.connectAddresses(b, bc.heightOf(b.CalHash()))
+		}
+	}
+}
+
+func (bc *Blockchain) indexTransactions(b *Block) {
+	hash := b.CalHash()
+	for pos, tx := range b.Transactions {
+		bc.db.Put(txLocationKey(tx.ID), toBytes(txLocation{BlockHash: hash, Pos: pos}))
+	}
+}
+
+// lookupTransaction find the transaction using the transaction index
+func (bc *Blockchain) lookupTransaction(txid Hash) (*TxInfo, error) {
+	v, err := bc.db.Get(txLocationKey(txid))
+	if err != nil {
+		return nil, errorTxNotFound
+	}
+	var loc txLocation
+	if err := toObject(v, &loc); err != nil {
+		return nil, errorTxNotFound
+	}
+	b := bc.getBlock(loc.BlockHash)
+	height, ok := bc.storedHeight(loc.BlockHash)
+	if b == nil || !ok || loc.Pos >= len(b.Transactions) {
+		return nil, errorTxNotFound
+	}
+	// entries written before a block left the active chain are ignored
+	if active, ok := bc.activeHash(height); !ok || !bytes.Equal(active, loc.BlockHash) {
+		return nil, errorTxNotFound
+	}
+	return &TxInfo{
+		Tx:            b.Transactions[loc.Pos],
+		BlockHash:     loc.BlockHash,
+		Height:        height,
+		Confirmations: bc.tipHeight() - height + 1,
+	}, nil
+}
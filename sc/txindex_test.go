@@ -0,0 +1,70 @@
+package sc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTxIndex(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+	blockchain.Send(miner, testParams.Address(alice), Coin)
+	sent := blockchain.getBlock(lastBlockKey).Transactions[1]
+
+	// an existing database gets indexed when the index is enabled
+	blockchain.EnableTxIndex()
+	if !blockchain.HasTxIndex() {
+		t.Fatalf("expected the index to be enabled")
+	}
+	blockchain.Mine(1)
+	info, err := blockchain.GetTransaction(sent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Height != 2 || info.Confirmations != 2 || !bytes.Equal(info.Tx.ID, sent.ID) {
+		t.Errorf("unexpected transaction info %+v", info)
+	}
+	latest := blockchain.getBlock(lastBlockKey).Transactions[0]
+	if info, err := blockchain.GetTransaction(latest.ID); err != nil || info.Confirmations != 1 {
+		t.Errorf("new blocks should be indexed: %v", err)
+	}
+
+	// a longer branch on top of block 1 replaces the payment to alice
+	fork := mineFork(blockchain, miner, 1, blockchain.Height())
+	if _, err := blockchain.GetTransaction(sent.ID); err != errorTxNotFound {
+		t.Errorf("disconnected transaction should be gone but got %v", err)
+	}
+	if _, err := blockchain.GetTransaction(fork[0].Transactions[0].ID); err != nil {
+		t.Errorf("connected transaction should be indexed: %v", err)
+	}
+}
+
+func TestGetTransactions(t *testing.T) {
+	miner := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(3)
+	first, _ := blockchain.GetBlockByHeight(1)
+	last, _ := blockchain.GetBlockByHeight(3)
+	txids := []Hash{first.Transactions[0].ID, last.Transactions[0].ID, Hash("unknown")}
+
+	// the chain scan and the index find the same transactions
+	for _, indexed := range []bool{false, true} {
+		if indexed {
+			blockchain.EnableTxIndex()
+		}
+		found := blockchain.GetTransactions(txids)
+		if len(found) != 2 {
+			t.Fatalf("expected 2 transactions but got %d", len(found))
+		}
+		if info := found[first.Transactions[0].ID.String()]; info.Height != 1 || info.Confirmations != 3 {
+			t.Errorf("unexpected transaction info %+v", info)
+		}
+		if info := found[last.Transactions[0].ID.String()]; info.Height != 3 || info.Confirmations != 1 {
+			t.Errorf("unexpected transaction info %+v", info)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package sc
 
 import (
 	"encoding/hex"
+	"encoding/json"
 )
 
 type Hash []byte
@@ -15,8 +16,42 @@ func StringToHash(v string) Hash {
 	return b
 }
 
+// MarshalJSON encode the hash as a hex string
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// UnmarshalJSON decode the hash from a hex string
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*h = b
+	return nil
+}
+
 type Address []byte
 
+// MarshalJSON encode the address as its base58 string
+func (a Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(a))
+}
+
+// UnmarshalJSON decode the address from its base58 string
+func (a *Address) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*a = Address(s)
+	return nil
+}
+
 type PubKey []byte
 
 type Stack struct {
@@ -37,6 +72,11 @@ func (s *Stack) Pop() []byte {
 	return v
 }
 
+// Len return the number of values on the stack
+func (s *Stack) Len() int {
+	return len(s.Values)
+}
+
 func (s *Stack) Peak() []byte {
 	return s.Values[len(s.Values)-1]
 }
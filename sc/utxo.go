@@ -0,0 +1,95 @@
+package sc
+
+import "errors"
+
+var errorMissingOutput = errors.New("error: this guy is trying to spend money which does not exist or was already spent")
+
+// outPoint identify an output of a transaction
+type outPoint struct {
+	txid string
+	vout int
+}
+
+// utxoEntry an unspent transaction output and how deep it is in the chain
+type utxoEntry struct {
+	out           TxOut
+	coinbase      bool
+	confirmations int
+}
+
+// utxoView the set of unspent transaction outputs at a given block
+type utxoView map[outPoint]*utxoEntry
+
+// utxoSet go over the blockchain backward from the block with the given hash and collect
+// the outputs which have not been spent yet
+//TODO should cache the unspent tractions output somewhere...don't need to scan entire the blockchain for this...
+func (bc *Blockchain) utxoSet(tip Hash) utxoView {
+	view := make(utxoView)
+	spent := make(map[outPoint]bool)
+	it := NewBlockIteratorFrom(bc.db, tip)
+	for depth, b := 0, it.Next(); b != nil; depth, b = depth+1, it.Next() {
+		for _, tx := range b.Transactions {
+			for _, vin := range tx.Vin {
+				if !vin.IsCoinBase() {
+					spent[outPoint{vin.Txid.String(), vin.Vout}] = true
+				}
+			}
+		}
+		for _, tx := range b.Transactions {
+			for idx, vout := range tx.Vout {
+				op := outPoint{tx.ID.String(), idx}
+				// data carriers are provably unspendable
+				if !spent[op] && !vout.IsDataCarrier() {
+					view[op] = &utxoEntry{out: vout, coinbase: tx.IsCoinBase(), confirmations: depth + 1}
+				}
+			}
+		}
+	}
+	return view
+}
+
+// validateTransaction check if the inputs exist, are not spent yet and can unlock the outputs they refer to.
+// It returns the fee of the transaction which is the total input minus the total output
+func (view utxoView) validateTransaction(tx *Transaction, params *ChainParams) (Amount, error) {
+	if err := tx.CheckSanity(params); err != nil {
+		return 0, err
+	}
+	inAmount := Amount(0)
+	for i, vin := range tx.Vin {
+		entry, ok := view[outPoint{vin.Txid.String(), vin.Vout}]
+		if !ok {
+			return 0, errorMissingOutput
+		}
+		if !tx.CanUnlock(i, entry.out) {
+			return 0, errorNotHisMoney
+		}
+		if entry.coinbase && !params.isMature(entry.confirmations) {
+			return 0, errorImmatureCoinbase
+		}
+		var err error
+		if inAmount, err = inAmount.Add(entry.out.Value); err != nil {
+			return 0, err
+		}
+	}
+	// check if the total amount in >= out amount...
+	fee, err := inAmount.Sub(tx.OutputValue())
+	if err != nil {
+		return 0, errorNotEnoughMoney
+	}
+	return fee, nil
+}
+
+// apply spend the outputs the transaction refers to and add its new outputs
+func (view utxoView) apply(tx *Transaction) {
+	for _, vin := range tx.Vin {
+		if !vin.IsCoinBase() {
+			delete(view, outPoint{vin.Txid.String(), vin.Vout})
+		}
+	}
+	for idx, vout := range tx.Vout {
+		if vout.IsDataCarrier() {
+			continue
+		}
+		view[outPoint{tx.ID.String(), idx}] = &utxoEntry{out: vout, coinbase: tx.IsCoinBase()}
+	}
+}
@@ -0,0 +1,135 @@
+package sc
+
+import "testing"
+
+// spend craft a transaction spending the given outputs to the given account
+func spend(bc *Blockchain, from *Account, to *Account, value Amount, ins ...TxIn) *Transaction {
+	tx := &Transaction{
+		Vin:  ins,
+		Vout: []TxOut{{Value: value, ScriptPubKey: bc.ScriptPubKey(testParams.Address(to))}},
+	}
+	for i, in := range ins {
+		var prevOut TxOut
+		if info, err := bc.GetTransaction(in.Txid); err == nil && in.Vout < len(info.Tx.Vout) {
+			prevOut = info.Tx.Vout[in.Vout]
+		}
+		tx.Vin[i].ScriptSig = bc.ScriptSig(from, tx, i, prevOut)
+	}
+	tx.SetID()
+	return tx
+}
+
+// mineBlock craft a block on top of the chain without validating its transactions
+func mineBlock(bc *Blockchain, miner *Account, txs ...*Transaction) *Block {
+	coinbase := NewCoinbase(bc.ScriptPubKey(testParams.Address(miner)), testParams.Subsidy(bc.heightOf(bc.tipHash())+1))
+	b := testParams.newBlock(append([]*Transaction{coinbase}, txs...), bc.tipHash())
+	b.Nonce = testParams.PoWer.Work(b)
+	return b
+}
+
+func TestDoubleSpendInsideTransaction(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+
+	coinbase := blockchain.getBlock(lastBlockKey).Transactions[0]
+	in := TxIn{Txid: coinbase.ID, Vout: 0}
+	tx := spend(blockchain, miner, alice, 10*Coin, in, in)
+	if _, err := blockchain.validateTransaction(tx); err != errorDuplicateInput {
+		t.Errorf("duplicate inputs should be rejected but got %v", err)
+	}
+	if blockchain.isValidBlock(mineBlock(blockchain, miner, tx)) {
+		t.Errorf("block with duplicate inputs should be rejected")
+	}
+}
+
+func TestDoubleSpendInsideBlock(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	bob := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+
+	coinbase := blockchain.getBlock(lastBlockKey).Transactions[0]
+	toAlice := spend(blockchain, miner, alice, 5*Coin, TxIn{Txid: coinbase.ID, Vout: 0})
+	toBob := spend(blockchain, miner, bob, 5*Coin, TxIn{Txid: coinbase.ID, Vout: 0})
+	if blockchain.isValidBlock(mineBlock(blockchain, miner, toAlice, toBob)) {
+		t.Errorf("block spending the same output twice should be rejected")
+	}
+	if !blockchain.isValidBlock(mineBlock(blockchain, miner, toAlice)) {
+		t.Errorf("block spending the output once should be accepted")
+	}
+
+	// the miner only picks one of the conflicting transactions and reports the other
+	if err := blockchain.MineNewBlock([]*Transaction{toAlice, toBob}); err != errorMissingOutput {
+		t.Errorf("expected the conflicting transaction to be reported but got %v", err)
+	}
+	if n := len(blockchain.getBlock(lastBlockKey).Transactions); n != 2 {
+		t.Errorf("block should contain the coinbase and 1 transaction but got %d", n)
+	}
+	if err := blockchain.Validate(); err != nil {
+		t.Errorf("invalid blockchain\n")
+	}
+}
+
+func TestDoubleSpendAcrossChain(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	bob := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(1)
+
+	coinbase := blockchain.getBlock(lastBlockKey).Transactions[0]
+	blockchain.MineNewBlock([]*Transaction{spend(blockchain, miner, alice, 5*Coin, TxIn{Txid: coinbase.ID, Vout: 0})})
+
+	toBob := spend(blockchain, miner, bob, 5*Coin, TxIn{Txid: coinbase.ID, Vout: 0})
+	if _, err := blockchain.validateTransaction(toBob); err != errorMissingOutput {
+		t.Errorf("spending an already spent output should be rejected but got %v", err)
+	}
+	if blockchain.isValidBlock(mineBlock(blockchain, miner, toBob)) {
+		t.Errorf("block spending an already spent output should be rejected")
+	}
+
+	unknown := spend(blockchain, miner, bob, 5*Coin, TxIn{Txid: hash256([]byte("unknown")), Vout: 0})
+	if _, err := blockchain.validateTransaction(unknown); err != errorMissingOutput {
+		t.Errorf("spending a nonexistent output should be rejected but got %v", err)
+	}
+	if err := blockchain.Validate(); err != nil {
+		t.Errorf("invalid blockchain\n")
+	}
+}
+
+func TestReplayedSignature(t *testing.T) {
+	miner := NewAccount()
+	alice := NewAccount()
+	mallory := NewAccount()
+	db, _ := NewMemDatabase()
+	blockchain := NewBlockchain(testParams, miner, db)
+	blockchain.Mine(2)
+
+	// alice receives two coins and spends the first one
+	blockchain.Send(miner, testParams.Address(alice), 1*Coin)
+	blockchain.Send(miner, testParams.Address(alice), 1*Coin)
+	_, _, utxos := blockchain.unspent(alice)
+	if len(utxos) != 2 {
+		t.Fatalf("alice should have 2 outputs but got %d", len(utxos))
+	}
+	paid := spend(blockchain, alice, miner, 1*Coin, utxos[0].TxIn)
+	if err := blockchain.SendTransaction(paid); err != nil {
+		t.Fatalf("alice should spend her output but got %v", err)
+	}
+
+	// her scriptSig is public now but doesn't unlock her other output
+	stolen := &Transaction{
+		Vin:  []TxIn{{Txid: utxos[1].TxIn.Txid, Vout: utxos[1].TxIn.Vout, ScriptSig: paid.Vin[0].ScriptSig}},
+		Vout: []TxOut{{Value: 1 * Coin, ScriptPubKey: blockchain.ScriptPubKey(testParams.Address(mallory))}},
+	}
+	stolen.SetID()
+	if _, err := blockchain.validateTransaction(stolen); err != errorNotHisMoney {
+		t.Errorf("replayed signature should be rejected but got %v", err)
+	}
+}
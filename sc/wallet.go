@@ -2,19 +2,24 @@ package sc
 
 import (
 	"fmt"
+	"io"
 )
 
 // Wallet represent a place to store priv/pub keys and allow to send money
 type Wallet interface {
 	Info(accName string)
-	Send(from, to string, amount int)
+	Send(from, to string, amount Amount)
+	Publish(accName string, doc []byte) Hash
 	Print()
 	Add(name string, acc *Account)
 }
 
-// MemWallet represent a memory wallet
+// MemWallet represent a memory wallet. Besides the accounts it holds the keys of, it can watch
+// addresses it doesn't hold the keys for
 type MemWallet struct {
 	accounts map[string]*Account
+	watched  map[string]Address
+	contacts map[string]Address
 	bc       *Blockchain
 }
 
@@ -22,32 +27,127 @@ type MemWallet struct {
 func NewMemWallet(bc *Blockchain) *MemWallet {
 	return &MemWallet{
 		accounts: make(map[string]*Account),
+		watched:  make(map[string]Address),
+		contacts: make(map[string]Address),
 		bc:       bc,
 	}
 }
 
 // Info print information of the account by the given name
 func (w *MemWallet) Info(accName string) {
-	balance, _ := w.bc.Spendable(w.accounts[accName], -1)
+	address, ok := w.address(accName)
+	if !ok {
+		fmt.Printf("error: <%s> account not found\n", accName)
+		return
+	}
+	balance, immature, _ := w.bc.BalanceOf(address)
+	_, watchOnly := w.watched[accName]
 	fmt.Printf(`
 	----------------------------------
 		Name: %s
-		Balance: %d $C
+		Address: %s
+		Watch-only: %v
+		Balance: %s
+		Immature: %s
 	----------------------------------
-		`, accName, balance)
+		`, accName, address, watchOnly, balance, immature)
+}
+
+// Send sending money from an account to another account, a contact or an address
+func (w *MemWallet) Send(from, to string, amount Amount) {
+	w.SendWithFee(from, to, amount, 0)
 }
 
-// Send sending money from an account to another account
-func (w *MemWallet) Send(from, to string, amount int) {
+// SendWithFee sending money from an account to another account, a contact or an address
+// and pay the given fee to the miner
+func (w *MemWallet) SendWithFee(from, to string, amount, fee Amount) {
+	w.SendUsing(from, to, amount, fee, nil)
+}
+
+// SendUsing sending money from an account to another account, a contact or an address, picking the inputs
+// with the given coin selector, or the largest outputs first if nil. It return how many inputs were spent and the change
+func (w *MemWallet) SendUsing(from, to string, amount, fee Amount, selector CoinSelector) SelectionStats {
+	if _, ok := w.watched[from]; ok {
+		fmt.Printf("error: <%s> is a watch-only account\n", from)
+		return SelectionStats{}
+	}
 	if _, ok := w.accounts[from]; !ok {
 		fmt.Printf("error: <%s> account not found\n", from)
-		return
+		return SelectionStats{}
 	}
-	if _, ok := w.accounts[to]; !ok {
-		fmt.Printf("error: <%s> account not found\n", to)
-		return
+	address, ok := w.recipient(to)
+	if !ok {
+		fmt.Printf("error: <%s> is neither an account, a contact nor a valid address\n", to)
+		return SelectionStats{}
+	}
+	return w.bc.SendUsing(w.accounts[from], address, amount, fee, selector)
+}
+
+// SendCSV pay the payouts read from CSV lines of <address>,<amount> in a single transaction
+// and return the transaction
+func (w *MemWallet) SendCSV(from string, r io.Reader) (*Transaction, error) {
+	acc, err := w.spender(from)
+	if err != nil {
+		return nil, err
 	}
-	w.bc.Send(w.accounts[from], w.accounts[to], amount)
+	addresses, amounts, err := ReadPayouts(r)
+	if err != nil {
+		return nil, err
+	}
+	builder := w.bc.NewTxBuilder(acc)
+	for i := range addresses {
+		if err := builder.AddOutput(addresses[i], amounts[i]); err != nil {
+			return nil, err
+		}
+	}
+	tx, err := builder.BuildSigned()
+	if err != nil {
+		return nil, err
+	}
+	return tx, w.bc.SendTransaction(tx)
+}
+
+// SendTo pay the given address from the account, pay the given fee to the miner and return the transaction
+func (w *MemWallet) SendTo(from string, to Address, amount, fee Amount) (*Transaction, error) {
+	acc, err := w.spender(from)
+	if err != nil {
+		return nil, err
+	}
+	builder := w.bc.NewTxBuilder(acc)
+	if err := builder.AddOutput(to, amount); err != nil {
+		return nil, err
+	}
+	if err := builder.SetFee(fee); err != nil {
+		return nil, err
+	}
+	tx, err := builder.BuildSigned()
+	if err != nil {
+		return nil, err
+	}
+	return tx, w.bc.SendTransaction(tx)
+}
+
+// spender return the account by the given name if the wallet holds its key
+func (w *MemWallet) spender(name string) (*Account, error) {
+	if _, ok := w.watched[name]; ok {
+		return nil, fmt.Errorf("error: <%s> is a watch-only account", name)
+	}
+	acc, ok := w.accounts[name]
+	if !ok {
+		return nil, fmt.Errorf("error: <%s> account not found", name)
+	}
+	return acc, nil
+}
+
+// Publish anchor the hash of the given document on the chain and return the hash
+func (w *MemWallet) Publish(accName string, doc []byte) Hash {
+	if _, ok := w.accounts[accName]; !ok {
+		fmt.Printf("error: <%s> account not found\n", accName)
+		return nil
+	}
+	h := hash256(doc)
+	w.bc.PublishData(w.accounts[accName], h)
+	return h
 }
 
 // Add a new account
@@ -55,15 +155,116 @@ func (w *MemWallet) Add(name string, acc *Account) {
 	w.accounts[name] = acc
 }
 
+// NewAddress create a new account by the given name and return its address
+func (w *MemWallet) NewAddress(name string) (Address, error) {
+	if _, ok := w.address(name); ok {
+		return nil, errorAccountExists
+	}
+	acc := NewAccount()
+	w.Add(name, acc)
+	return w.bc.params.Address(acc), nil
+}
+
+// Watch add a watch-only account tracking the given address
+func (w *MemWallet) Watch(name string, address Address) error {
+	if !w.bc.params.ValidateAddress(string(address)) {
+		return errorInvalidAddress
+	}
+	w.watched[name] = address
+	return nil
+}
+
+// AddContact add the address to the address book under the given name
+func (w *MemWallet) AddContact(name string, address Address) error {
+	if !w.bc.params.ValidateAddress(string(address)) {
+		return errorInvalidAddress
+	}
+	w.contacts[name] = address
+	return nil
+}
+
+// RemoveContact remove the contact by the given name from the address book
+func (w *MemWallet) RemoveContact(name string) {
+	delete(w.contacts, name)
+}
+
+// Contacts return a copy of the address book
+func (w *MemWallet) Contacts() map[string]Address {
+	contacts := make(map[string]Address, len(w.contacts))
+	for name, address := range w.contacts {
+		contacts[name] = address
+	}
+	return contacts
+}
+
 // Print info of all available accounts
 func (w *MemWallet) Print() {
 	for accName := range w.accounts {
 		w.Info(accName)
 	}
+	for accName := range w.watched {
+		w.Info(accName)
+	}
 }
 
-// Balance return balance of the given account
-func (w *MemWallet) Balance(accName string) int {
-	balance, _ := w.bc.Spendable(w.accounts[accName], -1)
+// Balance return the available balance of the given account
+func (w *MemWallet) Balance(accName string) Amount {
+	address, _ := w.address(accName)
+	balance, _, _ := w.bc.BalanceOf(address)
 	return balance
 }
+
+// ImmatureBalance return the amount of the given account locked in immature coinbase outputs
+func (w *MemWallet) ImmatureBalance(accName string) Amount {
+	address, _ := w.address(accName)
+	_, immature, _ := w.bc.BalanceOf(address)
+	return immature
+}
+
+// History return the transactions which received money to or spent money from the given account,
+// from the newest to the oldest, filtered and paginated by the given query
+func (w *MemWallet) History(accName string, q HistoryQuery) []AddressTx {
+	address, _ := w.address(accName)
+	history, err := w.bc.AddressHistory(address)
+	if err != nil {
+		fmt.Printf("error: <%s> account not found\n", accName)
+		return []AddressTx{}
+	}
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return q.Apply(history)
+}
+
+// recipient return the address to pay for the given account, watched account or contact name.
+// Any other valid address is paid as is
+func (w *MemWallet) recipient(to string) (Address, bool) {
+	if address, ok := w.address(to); ok {
+		return address, true
+	}
+	if address, ok := w.contacts[to]; ok {
+		return address, true
+	}
+	if w.bc.params.ValidateAddress(to) {
+		return Address(to), true
+	}
+	return nil, false
+}
+
+// AddressOf return the address of the account or watched account by the given name
+func (w *MemWallet) AddressOf(accName string) (Address, error) {
+	address, ok := w.address(accName)
+	if !ok {
+		return nil, errorAccountNotFound
+	}
+	return address, nil
+}
+
+// address return the address of the account or the watch-only account by the given name
+func (w *MemWallet) address(accName string) (Address, bool) {
+	if acc, ok := w.accounts[accName]; ok {
+		return w.bc.params.Address(acc), true
+	}
+	address, ok := w.watched[accName]
+	return address, ok
+}